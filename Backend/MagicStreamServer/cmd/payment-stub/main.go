@@ -0,0 +1,90 @@
+// Command payment-stub sends signed payment provider events to a local server,
+// so subscriptions can be tried out without a payment provider account.
+//
+//	go run ./cmd/payment-stub -user <user_id> -type invoice.paid -plan premium
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	billingservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/billing"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+)
+
+func main() {
+	url := flag.String("url", "http://localhost:5000/api/v1/webhooks/payments", "webhook endpoint")
+	secret := flag.String("secret", os.Getenv("PAYMENT_WEBHOOK_SECRET"), "webhook signing secret")
+	userID := flag.String("user", "", "user ID the subscription belongs to")
+	eventType := flag.String("type", "invoice.paid", "event type: subscription.trial_started, invoice.paid, invoice.payment_failed, subscription.updated or subscription.canceled")
+	plan := flag.String("plan", "", "plan: basic or premium")
+	periodDays := flag.Int("period-days", 30, "days until the paid period ends")
+	trialDays := flag.Int("trial-days", 14, "days until the trial ends")
+	customerID := flag.String("customer", "cus_stub", "provider customer ID")
+	subscriptionID := flag.String("subscription", "sub_stub", "provider subscription ID")
+	flag.Parse()
+
+	if *userID == "" || *secret == "" {
+		flag.Usage()
+		log.Fatal("-user and -secret (or PAYMENT_WEBHOOK_SECRET) are required")
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	event := models.PaymentEvent{
+		ID:      "evt_" + randomHex(8),
+		Type:    *eventType,
+		Created: now.Unix(),
+		Data: models.PaymentEventData{
+			UserID:         *userID,
+			CustomerID:     *customerID,
+			SubscriptionID: *subscriptionID,
+			Plan:           *plan,
+		},
+	}
+	switch *eventType {
+	case "subscription.trial_started":
+		trialEnd := now.AddDate(0, 0, *trialDays)
+		event.Data.TrialEndsAt = &trialEnd
+	case "invoice.paid", "subscription.canceled":
+		periodEnd := now.AddDate(0, 0, *periodDays)
+		event.Data.CurrentPeriodEnd = &periodEnd
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Fatal("Failed to encode event:", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(payload))
+	if err != nil {
+		log.Fatal("Failed to build request:", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(billingservice.SignatureHeader, billingservice.SignPayload(*secret, payload, now))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal("Failed to send event:", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("%s %s\n%s\n%s\n", event.Type, event.ID, resp.Status, body)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatal("Failed to generate event ID:", err)
+	}
+	return hex.EncodeToString(b)
+}
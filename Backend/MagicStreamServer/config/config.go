@@ -4,20 +4,59 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Port     string
-	MongoURI string
-	GinMode  string
-	DatabaseName string
-	BackendServerURI string
-	JWTAccessSecret      string
-	JWTRefreshSecret     string
-	AccessTokenExpireMin int
-	RefreshTokenExpireHr int
+	Port                       string
+	MongoURI                   string
+	GinMode                    string
+	DatabaseName               string
+	BackendServerURI           string
+	JWTAccessSecret            string
+	JWTRefreshSecret           string
+	AccessTokenExpireMin       int
+	RefreshTokenExpireHr       int
+	MovieTrashRetentionDays    int
+	MovieTrashPurgeIntervalMin int
+	MovieRequireIfMatch        bool
+	CacheControlMovieList      string
+	CacheControlMovieDetail    string
+	CacheControlMovieGenre     string
+	CacheControlGenres         string
+	WatchlistMaxItems          int
+	WatchProgressIntervalSec   int
+	WatchCompletionThreshold   float64
+	BlobStore                  string
+	BlobLocalDir               string
+	BlobPublicURL              string
+	S3Endpoint                 string
+	S3Region                   string
+	S3Bucket                   string
+	S3AccessKey                string
+	S3SecretKey                string
+	S3UseSSL                   bool
+	PosterMaxUploadMB          int
+	PosterWidths               []int
+	VideoMaxUploadMB           int
+	SubtitleMaxUploadKB        int
+	PlaybackSigningKeys        string
+	PlaybackURLTTLMin          int
+	PlaybackSessionTimeoutSec  int
+	MaxConcurrentStreams       int
+	StreamLimitsByRole         map[string]int
+	DefaultLocale              string
+	SupportedLocales           []string
+	ParentalPINMaxAttempts     int
+	ParentalPINLockoutMin      int
+	MaxProfiles                int
+	PlanMaxStreams             map[string]int
+	PlanMaxQuality             map[string]int
+	SubscriptionGraceDays      int
+	PaymentWebhookSecret       string
+	PaymentWebhookToleranceSec int
 }
 
 func LoadConfig() *Config {
@@ -28,17 +67,87 @@ func LoadConfig() *Config {
 
 	accessExp, _ := strconv.Atoi(getEnv("ACCESS_TOKEN_EXPIRE_MINUTES", "15"))
 	refreshExp, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_EXPIRE_HOURS", "168"))
+	trashRetention, _ := strconv.Atoi(getEnv("MOVIE_TRASH_RETENTION_DAYS", "30"))
+	trashPurgeInterval, _ := strconv.Atoi(getEnv("MOVIE_TRASH_PURGE_INTERVAL_MINUTES", "60"))
+	requireIfMatch, _ := strconv.ParseBool(getEnv("MOVIE_REQUIRE_IF_MATCH", "false"))
+	watchlistMax, _ := strconv.Atoi(getEnv("WATCHLIST_MAX_ITEMS", "500"))
+	progressInterval, _ := strconv.Atoi(getEnv("WATCH_PROGRESS_INTERVAL_SECONDS", "15"))
+	completionThreshold, _ := strconv.ParseFloat(getEnv("WATCH_COMPLETION_THRESHOLD", "0.9"), 64)
+	s3UseSSL, _ := strconv.ParseBool(getEnv("S3_USE_SSL", "false"))
+	posterMaxUpload, _ := strconv.Atoi(getEnv("POSTER_MAX_UPLOAD_MB", "10"))
+	videoMaxUpload, _ := strconv.Atoi(getEnv("VIDEO_MAX_UPLOAD_MB", "20480"))
+	subtitleMaxUpload, _ := strconv.Atoi(getEnv("SUBTITLE_MAX_UPLOAD_KB", "2048"))
+	playbackTTL, _ := strconv.Atoi(getEnv("PLAYBACK_URL_TTL_MINUTES", "240"))
+	sessionTimeout, _ := strconv.Atoi(getEnv("PLAYBACK_SESSION_TIMEOUT_SECONDS", "90"))
+	maxStreams, _ := strconv.Atoi(getEnv("MAX_CONCURRENT_STREAMS", "2"))
+	pinMaxAttempts, _ := strconv.Atoi(getEnv("PARENTAL_PIN_MAX_ATTEMPTS", "5"))
+	pinLockout, _ := strconv.Atoi(getEnv("PARENTAL_PIN_LOCKOUT_MINUTES", "15"))
+	maxProfiles, _ := strconv.Atoi(getEnv("MAX_PROFILES", "5"))
+	graceDays, _ := strconv.Atoi(getEnv("SUBSCRIPTION_GRACE_DAYS", "3"))
+	webhookTolerance, _ := strconv.Atoi(getEnv("PAYMENT_WEBHOOK_TOLERANCE_SECONDS", "300"))
+
+	port := getEnv("PORT", "5000")
+	backendURI := getEnv("BACKEND_URI", "")
+
+	// Local blobs are served by this server under /media
+	blobStore := getEnv("BLOB_STORE", "local")
+	blobPublicURL := getEnv("BLOB_PUBLIC_URL", "")
+	if blobPublicURL == "" && blobStore == "local" {
+		base := backendURI
+		if base == "" {
+			base = "http://localhost:" + port
+		}
+		blobPublicURL = strings.TrimSuffix(base, "/") + "/media"
+	}
 
 	return &Config{
-		Port: getEnv("PORT","5000"),
-		MongoURI: getEnv("MONGO_URI",""),
-		GinMode: getEnv("GIN_MODE","debug"),
-		DatabaseName: getEnv("DATABASE_NAME",""),
-		BackendServerURI: getEnv("BACKEND_URI",""),
-		JWTAccessSecret: getEnv("JWT_ACCESS_SECRET",""),
-		JWTRefreshSecret: getEnv("JWT_REFRESH_SECRET",""),
-		AccessTokenExpireMin: accessExp,
-		RefreshTokenExpireHr: refreshExp,
+		Port:                       port,
+		MongoURI:                   getEnv("MONGO_URI", ""),
+		GinMode:                    getEnv("GIN_MODE", "debug"),
+		DatabaseName:               getEnv("DATABASE_NAME", ""),
+		BackendServerURI:           backendURI,
+		JWTAccessSecret:            getEnv("JWT_ACCESS_SECRET", ""),
+		JWTRefreshSecret:           getEnv("JWT_REFRESH_SECRET", ""),
+		AccessTokenExpireMin:       accessExp,
+		RefreshTokenExpireHr:       refreshExp,
+		MovieTrashRetentionDays:    trashRetention,
+		MovieTrashPurgeIntervalMin: trashPurgeInterval,
+		MovieRequireIfMatch:        requireIfMatch,
+		CacheControlMovieList:      getEnvAllowEmpty("CACHE_CONTROL_MOVIE_LIST", "public, max-age=60, stale-while-revalidate=300"),
+		CacheControlMovieDetail:    getEnvAllowEmpty("CACHE_CONTROL_MOVIE_DETAIL", "public, max-age=300, stale-while-revalidate=600"),
+		CacheControlMovieGenre:     getEnvAllowEmpty("CACHE_CONTROL_MOVIE_GENRE", "public, max-age=60, stale-while-revalidate=300"),
+		CacheControlGenres:         getEnvAllowEmpty("CACHE_CONTROL_GENRES", "public, max-age=3600"),
+		WatchlistMaxItems:          watchlistMax,
+		WatchProgressIntervalSec:   progressInterval,
+		WatchCompletionThreshold:   completionThreshold,
+		BlobStore:                  blobStore,
+		BlobLocalDir:               getEnv("BLOB_LOCAL_DIR", "./uploads"),
+		BlobPublicURL:              blobPublicURL,
+		S3Endpoint:                 getEnv("S3_ENDPOINT", "localhost:9000"),
+		S3Region:                   getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:                   getEnv("S3_BUCKET", "magic-stream"),
+		S3AccessKey:                getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:                getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:                   s3UseSSL,
+		PosterMaxUploadMB:          posterMaxUpload,
+		PosterWidths:               getEnvInts("POSTER_WIDTHS", "185,342,500,780"),
+		VideoMaxUploadMB:           videoMaxUpload,
+		SubtitleMaxUploadKB:        subtitleMaxUpload,
+		PlaybackSigningKeys:        getEnv("PLAYBACK_SIGNING_KEYS", ""),
+		PlaybackURLTTLMin:          playbackTTL,
+		PlaybackSessionTimeoutSec:  sessionTimeout,
+		MaxConcurrentStreams:       maxStreams,
+		StreamLimitsByRole:         getEnvIntMap("STREAM_LIMITS_BY_ROLE", ""),
+		DefaultLocale:              getEnv("DEFAULT_LOCALE", "en"),
+		SupportedLocales:           getEnvList("SUPPORTED_LOCALES", "en,id"),
+		ParentalPINMaxAttempts:     pinMaxAttempts,
+		ParentalPINLockoutMin:      pinLockout,
+		MaxProfiles:                maxProfiles,
+		PlanMaxStreams:             getEnvIntMap("PLAN_MAX_STREAMS", "free:1,basic:2,premium:4"),
+		PlanMaxQuality:             getEnvIntMap("PLAN_MAX_QUALITY", "free:480,basic:1080,premium:2160"),
+		SubscriptionGraceDays:      graceDays,
+		PaymentWebhookSecret:       getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+		PaymentWebhookToleranceSec: webhookTolerance,
 	}
 }
 
@@ -47,5 +156,48 @@ func getEnv(key, defaulValue string) string {
 	if value == "" {
 		return defaulValue
 	}
-	return  value
-}
\ No newline at end of file
+	return value
+}
+
+// getEnvInts reads a comma separated list of integers, skipping invalid entries
+func getEnvInts(key, defaultValue string) []int {
+	var values []int
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && value > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvList reads a comma separated list of strings, skipping empty entries
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value := strings.TrimSpace(part); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvIntMap parses a comma separated list of name:number pairs, skipping
+// malformed entries
+func getEnvIntMap(key, defaultValue string) map[string]int {
+	values := map[string]int{}
+	for _, part := range strings.Split(getEnv(key, defaultValue), ",") {
+		name, number, found := strings.Cut(strings.TrimSpace(part), ":")
+		if value, err := strconv.Atoi(strings.TrimSpace(number)); found && name != "" && err == nil && value >= 0 {
+			values[strings.TrimSpace(name)] = value
+		}
+	}
+	return values
+}
+
+// getEnvAllowEmpty is like getEnv but keeps a variable that is explicitly set to ""
+func getEnvAllowEmpty(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 var (
@@ -22,6 +23,12 @@ type TokenService struct {
 	refreshTokenRepo repositories.RefreshTokenRepository
 }
 
+// AccessClaims identify the caller of a request
+type AccessClaims struct {
+	UserID    string
+	ProfileID bson.ObjectID // zero in tokens issued before profiles, meaning the default profile
+}
+
 func NewTokenService(cfg *config.Config, refreshTokenRepo repositories.RefreshTokenRepository) *TokenService {
 	return &TokenService{
 		cfg:              cfg,
@@ -29,12 +36,13 @@ func NewTokenService(cfg *config.Config, refreshTokenRepo repositories.RefreshTo
 	}
 }
 
-// GenerateTokenPair creates new access + refresh tokens
-func (ts *TokenService) GenerateTokenPair(userID string) (*models.TokenPair, error) {
+// GenerateTokenPair creates new access + refresh tokens for a profile of the user
+func (ts *TokenService) GenerateTokenPair(userID string, profileID bson.ObjectID) (*models.TokenPair, error) {
 	// === Access Token ===
 	accessExp := time.Now().Add(time.Minute * time.Duration(ts.cfg.AccessTokenExpireMin))
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": userID,
+		"pid": profileID.Hex(),
 		"exp": accessExp.Unix(),
 		"typ": "access",
 	})
@@ -47,6 +55,7 @@ func (ts *TokenService) GenerateTokenPair(userID string) (*models.TokenPair, err
 	refreshExp := time.Now().Add(time.Hour * time.Duration(ts.cfg.RefreshTokenExpireHr))
 	refreshJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": userID,
+		"pid": profileID.Hex(),
 		"exp": refreshExp.Unix(),
 		"typ": "refresh",
 	})
@@ -58,6 +67,7 @@ func (ts *TokenService) GenerateTokenPair(userID string) (*models.TokenPair, err
 	// === Store refresh token in DB ===
 	refreshTokenDoc := models.RefreshToken{
 		UserID:    userID,
+		ProfileID: profileID,
 		Token:     refreshStr,
 		ExpiresAt: refreshExp,
 		CreatedAt: time.Now(),
@@ -78,6 +88,15 @@ func (ts *TokenService) GenerateTokenPair(userID string) (*models.TokenPair, err
 
 // ValidateAccessToken validates a JWT access token and returns the user ID.
 func (ts *TokenService) ValidateAccessToken(tokenStr string) (string, error) {
+	claims, err := ts.ParseAccessToken(tokenStr)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// ParseAccessToken validates a JWT access token and returns the user and profile it was issued for.
+func (ts *TokenService) ParseAccessToken(tokenStr string) (*AccessClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -85,25 +104,30 @@ func (ts *TokenService) ValidateAccessToken(tokenStr string) (string, error) {
 		return []byte(ts.cfg.JWTAccessSecret), nil
 	})
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok || !token.Valid {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	typ, ok := claims["typ"].(string)
 	if !ok || typ != "access" {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	userID, ok := claims["sub"].(string)
 	if !ok || userID == "" {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
+	}
+
+	profileID, err := profileIDClaim(claims)
+	if err != nil {
+		return nil, err
 	}
 
-	return userID, nil
+	return &AccessClaims{UserID: userID, ProfileID: profileID}, nil
 }
 
 // RevokeRefreshTokens revokes all active refresh tokens for a given user.
@@ -112,6 +136,12 @@ func (ts *TokenService) RevokeRefreshTokens(userID string) error {
 	return ts.refreshTokenRepo.RevokeUserTokens(ctx, userID)
 }
 
+// RevokeProfileTokens revokes the active refresh tokens issued for one profile of a user.
+func (ts *TokenService) RevokeProfileTokens(userID string, profileID bson.ObjectID) error {
+	ctx := context.TODO()
+	return ts.refreshTokenRepo.RevokeProfileTokens(ctx, userID, profileID)
+}
+
 // UseRefreshToken validates a refresh token and issues a new token pair.
 func (ts *TokenService) UseRefreshToken(refreshToken string) (*models.TokenPair, error) {
 	// 1. Validate JWT signature and claims
@@ -139,6 +169,11 @@ func (ts *TokenService) UseRefreshToken(refreshToken string) (*models.TokenPair,
 		return nil, ErrInvalidToken
 	}
 
+	profileID, err := profileIDClaim(claims)
+	if err != nil {
+		return nil, err
+	}
+
 	// 2. Look up token in DB
 	ctx := context.TODO()
 	stored, err := ts.refreshTokenRepo.FindByToken(ctx, refreshToken, userID)
@@ -164,7 +199,21 @@ func (ts *TokenService) UseRefreshToken(refreshToken string) (*models.TokenPair,
 	_ = ts.refreshTokenRepo.RevokeToken(ctx, stored.ID.Hex())
 
 	// 5. Issue new token pair
-	return ts.GenerateTokenPair(userID)
+	return ts.GenerateTokenPair(userID, profileID)
+}
+
+// profileIDClaim reads the profile of a token. Tokens from before profiles
+// have none and stand for the default profile.
+func profileIDClaim(claims jwt.MapClaims) (bson.ObjectID, error) {
+	pid, ok := claims["pid"].(string)
+	if !ok || pid == "" {
+		return bson.ObjectID{}, nil
+	}
+	profileID, err := bson.ObjectIDFromHex(pid)
+	if err != nil {
+		return bson.ObjectID{}, ErrInvalidToken
+	}
+	return profileID, nil
 }
 
 // CleanupExpiredRefreshTokens removes all expired refresh tokens from the database.
@@ -0,0 +1,154 @@
+package playbackservice
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+)
+
+var (
+	ErrInvalidPlaybackToken = errors.New("invalid playback token")
+	ErrExpiredPlaybackToken = errors.New("playback token has expired")
+)
+
+// ScopeHLS grants a movie's HLS playlists and segments
+const ScopeHLS = "hls"
+
+// VideoScope grants a single progressive video asset of a movie
+func VideoScope(asset string) string {
+	return "video:" + asset
+}
+
+// PlaybackClaims are carried inside a signed playback token. Object holds the
+// blob key of a video asset, so the asset is served without loading the movie.
+// MaxQuality is the highest rendition height the caller's plan allowed when
+// the token was signed, 0 meaning no cap.
+type PlaybackClaims struct {
+	KeyID      string `json:"kid"`
+	SessionID  string `json:"sid"`
+	UserID     string `json:"sub"`
+	MovieID    string `json:"mid"`
+	Scope      string `json:"scp"`
+	Object     string `json:"obj,omitempty"`
+	MaxQuality int    `json:"mxq,omitempty"`
+	ExpiresAt  int64  `json:"exp"`
+}
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// minSecretLength keeps keys long enough for HMAC-SHA256 to be meaningful
+const minSecretLength = 32
+
+// URLSigner signs and verifies playback tokens without touching the database.
+// Tokens are signed with the first configured key and verified with any of
+// them, so keys rotate by adding a new key in front and dropping the old one
+// once the tokens it signed have expired.
+type URLSigner struct {
+	activeKeyID string
+	keys        map[string][]byte
+	ttl         time.Duration
+}
+
+// NewURLSigner reads the keys from PLAYBACK_SIGNING_KEYS ("id:secret,..."),
+// falling back to a key derived from the access token secret
+func NewURLSigner(cfg *config.Config) (*URLSigner, error) {
+	signer := &URLSigner{
+		keys: map[string][]byte{},
+		ttl:  time.Duration(cfg.PlaybackURLTTLMin) * time.Minute,
+	}
+	if signer.ttl <= 0 {
+		return nil, errors.New("PLAYBACK_URL_TTL_MINUTES must be positive")
+	}
+
+	if cfg.PlaybackSigningKeys == "" {
+		if cfg.JWTAccessSecret == "" {
+			return nil, errors.New("PLAYBACK_SIGNING_KEYS is required")
+		}
+		mac := hmac.New(sha256.New, []byte(cfg.JWTAccessSecret))
+		mac.Write([]byte("playback-url"))
+		signer.activeKeyID = "default"
+		signer.keys["default"] = mac.Sum(nil)
+		return signer, nil
+	}
+
+	for _, entry := range strings.Split(cfg.PlaybackSigningKeys, ",") {
+		id, secret, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("PLAYBACK_SIGNING_KEYS entries must look like id:secret")
+		}
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("playback signing key %s must be at least %d characters", id, minSecretLength)
+		}
+		if _, exists := signer.keys[id]; exists {
+			return nil, fmt.Errorf("playback signing key %s is configured twice", id)
+		}
+		if signer.activeKeyID == "" {
+			signer.activeKeyID = id
+		}
+		signer.keys[id] = []byte(secret)
+	}
+
+	return signer, nil
+}
+
+// TTL returns how long signed playback URLs stay valid
+func (s *URLSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign returns a URL safe token carrying the claims, valid until expiresAt
+func (s *URLSigner) Sign(claims PlaybackClaims, expiresAt time.Time) (string, error) {
+	claims.KeyID = s.activeKeyID
+	claims.ExpiresAt = expiresAt.Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signature(s.keys[s.activeKeyID], encoded), nil
+}
+
+// Verify checks the signature and expiry of a token and returns its claims
+func (s *URLSigner) Verify(token string) (*PlaybackClaims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidPlaybackToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidPlaybackToken
+	}
+
+	var claims PlaybackClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidPlaybackToken
+	}
+
+	key, ok := s.keys[claims.KeyID]
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signature(key, encoded))) {
+		return nil, ErrInvalidPlaybackToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredPlaybackToken
+	}
+
+	return &claims, nil
+}
+
+func (s *URLSigner) signature(key []byte, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
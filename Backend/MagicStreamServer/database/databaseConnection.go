@@ -70,4 +70,13 @@ func OpenCollection(collectionName string) *mongo.Collection {
 
 	collection := Client.Database(dbName).Collection(collectionName)
 	return collection
-}
\ No newline at end of file
+}
+func OpenDatabase() *mongo.Database {
+	cfg := config.LoadConfig()
+
+	if Client == nil {
+		log.Fatal("MongoDB client is not initialized. Call Connect() first!")
+	}
+
+	return Client.Database(cfg.DatabaseName)
+}
@@ -1,18 +1,30 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"mime"
+	"path/filepath"
+	"time"
 
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
 	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	playbackservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/playback"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/database"
 	_ "github.com/afdhali/magic-stream/Backend/MagicStreamServer/docs"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/migrations"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/routes"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/storage"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // @title           Magic Stream API
@@ -58,17 +70,53 @@ func main() {
 	database.Connect()
 	defer database.Disconnect()
 
+	// Apply pending data migrations
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 60*time.Second)
+	if err := migrations.Run(migrateCtx, database.OpenDatabase()); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+	cancelMigrate()
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(database.OpenCollection("users"))
 	movieRepo := repositories.NewMovieRepository(database.OpenCollection("movies"))
 	genreRepo := repositories.NewGenreRepository(database.OpenCollection("genres"))
+	personRepo := repositories.NewPersonRepository(database.OpenCollection("people"))
+	revisionRepo := repositories.NewMovieRevisionRepository(database.OpenCollection("movie_revisions"))
+	rankingRepo := repositories.NewRankingRepository(database.OpenCollection("rankings"))
+	reviewRepo := repositories.NewReviewRepository(database.OpenCollection("reviews"))
+	watchlistRepo := repositories.NewWatchlistRepository(database.OpenCollection("watchlist"))
+	historyRepo := repositories.NewWatchHistoryRepository(database.OpenCollection("watch_history"))
+	collectionRepo := repositories.NewCollectionRepository(database.OpenCollection("collections"))
+	playlistRepo := repositories.NewHLSPlaylistRepository(database.OpenCollection("hls_playlists"))
+	playbackSessionRepo := repositories.NewPlaybackSessionRepository(database.OpenCollection("playback_sessions"))
+	paymentEventRepo := repositories.NewPaymentEventRepository(database.OpenCollection("payment_events"))
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(database.OpenCollection("refresh_token"))
 
+	// Initialize blob storage for uploaded media
+	storeCtx, cancelStore := context.WithTimeout(context.Background(), 30*time.Second)
+	blobStore, err := storage.NewBlobStore(storeCtx, cfg)
+	cancelStore()
+	if err != nil {
+		log.Fatal("Failed to initialize blob store:", err)
+	}
+
 	// Initialize services
 	tokenService := authservice.NewTokenService(cfg, refreshTokenRepo)
+	playbackSigner, err := playbackservice.NewURLSigner(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize playback URL signer:", err)
+	}
+	locales, err := utils.NewLocales(cfg.DefaultLocale, cfg.SupportedLocales)
+	if err != nil {
+		log.Fatal("Failed to initialize locales:", err)
+	}
+
+	// Background jobs
+	startMovieTrashPurge(movieRepo, reviewRepo, watchlistRepo, historyRepo, collectionRepo, playlistRepo, blobStore, cfg)
 
 	// Setup routes
-	setupRoutes(router, tokenService, userRepo, movieRepo, genreRepo)
+	setupRoutes(router, cfg, tokenService, userRepo, movieRepo, genreRepo, personRepo, revisionRepo, rankingRepo, reviewRepo, watchlistRepo, historyRepo, collectionRepo, playlistRepo, playbackSessionRepo, paymentEventRepo, blobStore, playbackSigner, locales)
 
 	// Start server
 	fmt.Printf("🚀 Server running on http://localhost:%s\n", cfg.Port)
@@ -81,9 +129,13 @@ func main() {
 }
 
 // setupRoutes configures all application routes
-func setupRoutes(router *gin.Engine, ts *authservice.TokenService, userRepo repositories.UserRepository, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository) {
-	// API v1 group
-	v1 := router.Group("/api/v1")
+func setupRoutes(router *gin.Engine, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, personRepo repositories.PersonRepository, revisionRepo repositories.MovieRevisionRepository, rankingRepo repositories.RankingRepository, reviewRepo repositories.ReviewRepository, watchlistRepo repositories.WatchlistRepository, historyRepo repositories.WatchHistoryRepository, collectionRepo repositories.CollectionRepository, playlistRepo repositories.HLSPlaylistRepository, playbackSessionRepo repositories.PlaybackSessionRepository, paymentEventRepo repositories.PaymentEventRepository, blobStore storage.BlobStore, signer *playbackservice.URLSigner, locales *utils.Locales) {
+	// Uploaded media served from the local blob store
+	setupMediaRoutes(router, blobStore)
+
+	// API v1 group, localized handlers read the negotiated locale chain and
+	// catalog reads the maturity limit of the signed-in caller's profile
+	v1 := router.Group("/api/v1", middleware.Locale(locales), middleware.ActiveProfile(ts, userRepo))
 
 	// Health check endpoint
 	v1.GET("/health", func(c *gin.Context) {
@@ -95,17 +147,50 @@ func setupRoutes(router *gin.Engine, ts *authservice.TokenService, userRepo repo
 	})
 
 	// Feature routes
-	setupAuthRoutes(v1, ts, userRepo, genreRepo)
-	setupGenreRoutes(v1, ts, genreRepo)
-	setupMovieRoutes(v1, ts, movieRepo, genreRepo)
+	setupAuthRoutes(v1, cfg, ts, userRepo, genreRepo)
+	setupGenreRoutes(v1, cfg, ts, genreRepo, movieRepo, locales)
+	setupMovieRoutes(v1, cfg, ts, movieRepo, genreRepo, personRepo, revisionRepo, rankingRepo, watchlistRepo, blobStore, playlistRepo, locales)
+	setupStreamRoutes(v1, cfg, ts, userRepo, movieRepo, playlistRepo, playbackSessionRepo, blobStore, signer)
+	setupReviewRoutes(v1, ts, reviewRepo, movieRepo, userRepo)
+	setupWatchlistRoutes(v1, cfg, ts, watchlistRepo, movieRepo)
+	setupHistoryRoutes(v1, cfg, ts, historyRepo, movieRepo)
+	setupParentalControlsRoutes(v1, cfg, ts, userRepo)
+	setupProfileRoutes(v1, cfg, ts, userRepo, genreRepo, watchlistRepo, historyRepo)
+	setupSubscriptionRoutes(v1, cfg, ts, userRepo, paymentEventRepo)
+	setupCollectionRoutes(v1, ts, collectionRepo, movieRepo, rankingRepo)
+	setupHomeRoutes(v1, ts, movieRepo, genreRepo, collectionRepo, historyRepo, rankingRepo)
+	setupPeopleRoutes(v1, ts, personRepo, movieRepo)
+	setupRankingRoutes(v1, ts, rankingRepo, movieRepo)
+	setupAdminRoutes(v1, cfg, ts, movieRepo, genreRepo, personRepo, revisionRepo, rankingRepo, watchlistRepo, blobStore, playlistRepo, locales)
+}
+
+// setupMediaRoutes serves the public part of the local blob store under /media.
+// Blob keys change whenever their content does, so the files can be cached
+// forever. Videos stay private and are only served by /stream. Other stores
+// serve their blobs themselves.
+func setupMediaRoutes(router *gin.Engine, blobStore storage.BlobStore) {
+	local, ok := blobStore.(*storage.LocalBlobStore)
+	if !ok {
+		return
+	}
+
+	// Not every system's mime.types knows WebVTT
+	mime.AddExtensionType(".vtt", utils.WebVTTContentType)
+
+	media := router.Group("/media", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Next()
+	})
+	media.Static("/posters", filepath.Join(local.Dir(), "posters"))
+	media.Static("/subtitles", filepath.Join(local.Dir(), "subtitles"))
 }
 
 // setupAuthRoutes configures authentication related routes
-func setupAuthRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository) {
+func setupAuthRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository) {
 	auth := rg.Group("/auth")
 
 	// Initialize auth handler with token service
-	authHandler := routes.NewAuthHandler(ts, userRepo, genreRepo)
+	authHandler := routes.NewAuthHandler(ts, cfg, userRepo, genreRepo)
 
 	// Public routes (no authentication required)
 	auth.POST("/register", authHandler.Register)
@@ -119,13 +204,13 @@ func setupAuthRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, userRepo
 }
 
 // setupGenreRoutes configures genre related routes
-func setupGenreRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, genreRepo repositories.GenreRepository) {
+func setupGenreRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, genreRepo repositories.GenreRepository, movieRepo repositories.MovieRepository, locales *utils.Locales) {
 	genres := rg.Group("/genres")
 
-	genreHandler := routes.NewGenreHandler(ts, genreRepo)
+	genreHandler := routes.NewGenreHandler(ts, genreRepo, movieRepo, locales)
 
 	// Public routes
-	genres.GET("", genreHandler.GetAllGenres)
+	genres.GET("", middleware.CacheControl(cfg.CacheControlGenres), genreHandler.GetAllGenres)
 	genres.GET("/:id", genreHandler.GetGenreByID)
 
 	// Protected routes (admin only)
@@ -134,18 +219,27 @@ func setupGenreRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, genreRe
 		middleware.AdminOnly(),
 		genreHandler.SeedGenres,
 	)
+	genres.PUT("/:id/translations",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		genreHandler.UpdateTranslations,
+	)
 }
 
 // setupMovieRoutes configures movie related routes
-func setupMovieRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository) {
+func setupMovieRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, personRepo repositories.PersonRepository, revisionRepo repositories.MovieRevisionRepository, rankingRepo repositories.RankingRepository, watchlistRepo repositories.WatchlistRepository, blobStore storage.BlobStore, playlistRepo repositories.HLSPlaylistRepository, locales *utils.Locales) {
 	movies := rg.Group("/movies")
 
-	movieHandler := routes.NewMovieHandler(ts, movieRepo, genreRepo)
+	movieHandler := routes.NewMovieHandler(ts, cfg, movieRepo, genreRepo, personRepo, revisionRepo, rankingRepo, watchlistRepo, blobStore, playlistRepo, locales)
 
 	// Public routes
-	movies.GET("", movieHandler.GetAll)
-	movies.GET("/:id", movieHandler.GetByID)
-	movies.GET("/genre/:genre_id", movieHandler.GetByGenre)
+	movies.GET("", middleware.OptionalAuth(ts), middleware.CacheControl(cfg.CacheControlMovieList), movieHandler.GetAll)
+	movies.GET("/facets", movieHandler.GetFacets)
+	movies.GET("/search", middleware.OptionalAuth(ts), movieHandler.Search)
+	movies.GET("/autocomplete", movieHandler.Autocomplete)
+	movies.GET("/:id", middleware.OptionalAuth(ts), middleware.CacheControl(cfg.CacheControlMovieDetail), movieHandler.GetByID)
+	movies.GET("/:id/credits", movieHandler.GetCredits)
+	movies.GET("/genre/:genre_id", middleware.CacheControl(cfg.CacheControlMovieGenre), movieHandler.GetByGenre)
 
 	// Protected routes (user must be authenticated)
 	movies.GET("/recommendations",
@@ -164,9 +258,384 @@ func setupMovieRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, movieRe
 		middleware.AdminOnly(),
 		movieHandler.Update,
 	)
+	movies.POST("/:id/poster",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.UploadPoster,
+	)
+	movies.PUT("/:id/videos/:asset",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.UploadVideo,
+	)
+	movies.DELETE("/:id/videos/:asset",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.DeleteVideo,
+	)
+	movies.PUT("/:id/renditions/:rendition",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.UploadRendition,
+	)
+	movies.DELETE("/:id/renditions/:rendition",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.DeleteRendition,
+	)
+	movies.PUT("/:id/subtitles/:lang",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.UploadSubtitle,
+	)
+	movies.DELETE("/:id/subtitles/:lang",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.DeleteSubtitle,
+	)
+	movies.PATCH("/:id",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		movieHandler.Patch,
+	)
 	movies.DELETE("/:id",
 		middleware.AuthMiddleware(ts),
 		middleware.AdminOnly(),
 		movieHandler.Delete,
 	)
-}
\ No newline at end of file
+
+	// Revision history (admin only)
+	revisions := movies.Group("/:id/revisions", middleware.AuthMiddleware(ts), middleware.AdminOnly())
+	revisions.GET("", movieHandler.GetRevisions)
+	revisions.GET("/diff", movieHandler.DiffRevisions)
+	revisions.GET("/:rev", movieHandler.GetRevision)
+	revisions.POST("/:rev/revert", movieHandler.RevertRevision)
+}
+
+// setupReviewRoutes configures user rating and review routes
+func setupReviewRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, reviewRepo repositories.ReviewRepository, movieRepo repositories.MovieRepository, userRepo repositories.UserRepository) {
+	reviews := rg.Group("/movies/:id/reviews")
+
+	reviewHandler := routes.NewReviewHandler(ts, reviewRepo, movieRepo, userRepo)
+
+	// Public routes
+	reviews.GET("", reviewHandler.GetByMovie)
+
+	// Protected routes (require authentication)
+	reviews.POST("", middleware.AuthMiddleware(ts), reviewHandler.Create)
+	reviews.PUT("", middleware.AuthMiddleware(ts), reviewHandler.Update)
+	reviews.DELETE("", middleware.AuthMiddleware(ts), reviewHandler.Delete)
+	reviews.POST("/:review_id/helpful", middleware.AuthMiddleware(ts), reviewHandler.MarkHelpful)
+}
+
+// setupWatchlistRoutes configures the signed-in user's watchlist routes
+func setupWatchlistRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, watchlistRepo repositories.WatchlistRepository, movieRepo repositories.MovieRepository) {
+	watchlist := rg.Group("/me/watchlist", middleware.AuthMiddleware(ts))
+
+	watchlistHandler := routes.NewWatchlistHandler(ts, cfg, watchlistRepo, movieRepo)
+
+	watchlist.GET("", watchlistHandler.GetAll)
+	watchlist.PUT("/:movie_id", watchlistHandler.Put)
+	watchlist.DELETE("/:movie_id", watchlistHandler.Delete)
+}
+
+// setupHistoryRoutes configures the signed-in user's watch history routes
+func setupHistoryRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, historyRepo repositories.WatchHistoryRepository, movieRepo repositories.MovieRepository) {
+	me := rg.Group("/me", middleware.AuthMiddleware(ts))
+
+	historyHandler := routes.NewHistoryHandler(ts, cfg, historyRepo, movieRepo)
+
+	me.POST("/progress", historyHandler.UpdateProgress)
+	me.GET("/history", historyHandler.GetHistory)
+	me.GET("/continue-watching", historyHandler.GetContinueWatching)
+}
+
+// setupParentalControlsRoutes configures the parental PIN of the caller
+func setupParentalControlsRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository) {
+	parental := rg.Group("/me/parental-controls", middleware.AuthMiddleware(ts))
+
+	parentalHandler := routes.NewParentalControlsHandler(ts, cfg, userRepo)
+
+	parental.GET("", parentalHandler.Get)
+	parental.PUT("", parentalHandler.Put)
+	parental.DELETE("", parentalHandler.Delete)
+}
+
+// setupProfileRoutes configures the viewer profiles of the caller
+func setupProfileRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository, watchlistRepo repositories.WatchlistRepository, historyRepo repositories.WatchHistoryRepository) {
+	profiles := rg.Group("/me/profiles", middleware.AuthMiddleware(ts))
+
+	profileHandler := routes.NewProfileHandler(ts, cfg, userRepo, genreRepo, watchlistRepo, historyRepo)
+
+	profiles.GET("", profileHandler.GetAll)
+	profiles.POST("", profileHandler.Create)
+	profiles.PUT("/:profile_id", profileHandler.Update)
+	profiles.DELETE("/:profile_id", profileHandler.Delete)
+	profiles.POST("/:profile_id/select", profileHandler.Select)
+}
+
+// setupSubscriptionRoutes configures the plan, subscription and payment webhook routes
+func setupSubscriptionRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository, paymentEventRepo repositories.PaymentEventRepository) {
+	subscriptionHandler := routes.NewSubscriptionHandler(ts, cfg, userRepo, paymentEventRepo)
+
+	// Public routes
+	rg.GET("/plans", subscriptionHandler.GetPlans)
+
+	rg.GET("/me/subscription", middleware.AuthMiddleware(ts), subscriptionHandler.GetMine)
+
+	// Authenticated by the payload signature
+	rg.POST("/webhooks/payments", subscriptionHandler.PaymentWebhook)
+}
+
+// setupCollectionRoutes configures the curated collection routes
+func setupCollectionRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, collectionRepo repositories.CollectionRepository, movieRepo repositories.MovieRepository, rankingRepo repositories.RankingRepository) {
+	collections := rg.Group("/collections")
+
+	collectionHandler := routes.NewCollectionHandler(ts, collectionRepo, movieRepo, rankingRepo)
+
+	// Public routes
+	collections.GET("", collectionHandler.GetAll)
+	collections.GET("/:id", collectionHandler.GetByID)
+	collections.GET("/:id/movies", collectionHandler.GetMovies)
+
+	// Admin only routes
+	collections.POST("",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		collectionHandler.Create,
+	)
+	collections.PUT("/:id",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		collectionHandler.Update,
+	)
+	collections.DELETE("/:id",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		collectionHandler.Delete,
+	)
+}
+
+// setupHomeRoutes configures the home page routes, personalised for signed-in callers
+func setupHomeRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, collectionRepo repositories.CollectionRepository, historyRepo repositories.WatchHistoryRepository, rankingRepo repositories.RankingRepository) {
+	home := rg.Group("/home", middleware.OptionalAuth(ts))
+
+	homeHandler := routes.NewHomeHandler(ts, movieRepo, genreRepo, collectionRepo, historyRepo, rankingRepo)
+
+	home.GET("", homeHandler.GetHome)
+	home.GET("/rails/:id", homeHandler.GetRail)
+}
+
+// setupPeopleRoutes configures cast and crew related routes
+func setupPeopleRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, personRepo repositories.PersonRepository, movieRepo repositories.MovieRepository) {
+	people := rg.Group("/people")
+
+	personHandler := routes.NewPersonHandler(ts, personRepo, movieRepo)
+
+	// Public routes
+	people.GET("", personHandler.GetAll)
+	people.GET("/:id", personHandler.GetByID)
+	people.GET("/:id/movies", personHandler.GetMovies)
+
+	// Admin only routes
+	people.POST("",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		personHandler.Create,
+	)
+	people.PUT("/:id",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		personHandler.Update,
+	)
+	people.DELETE("/:id",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		personHandler.Delete,
+	)
+	people.POST("/:id/merge",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		personHandler.Merge,
+	)
+}
+
+// setupRankingRoutes configures the rankings catalog routes
+func setupRankingRoutes(rg *gin.RouterGroup, ts *authservice.TokenService, rankingRepo repositories.RankingRepository, movieRepo repositories.MovieRepository) {
+	rankings := rg.Group("/rankings")
+
+	rankingHandler := routes.NewRankingHandler(ts, rankingRepo, movieRepo)
+
+	// Public routes
+	rankings.GET("", rankingHandler.GetAll)
+	rankings.GET("/:value", rankingHandler.GetByValue)
+
+	// Admin only routes
+	rankings.POST("",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		rankingHandler.Create,
+	)
+	rankings.PUT("/:value",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		rankingHandler.Update,
+	)
+	rankings.DELETE("/:value",
+		middleware.AuthMiddleware(ts),
+		middleware.AdminOnly(),
+		rankingHandler.Delete,
+	)
+}
+
+// setupStreamRoutes configures video streaming routes (authenticated by bearer
+// token, or by a signed playback URL under /play)
+func setupStreamRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, userRepo repositories.UserRepository, movieRepo repositories.MovieRepository, playlistRepo repositories.HLSPlaylistRepository, playbackSessionRepo repositories.PlaybackSessionRepository, blobStore storage.BlobStore, signer *playbackservice.URLSigner) {
+	stream := rg.Group("/stream", middleware.AuthMiddleware(ts))
+
+	streamHandler := routes.NewStreamHandler(ts, cfg, movieRepo, blobStore)
+
+	stream.GET("/:movie_id/:asset", streamHandler.Stream)
+	stream.HEAD("/:movie_id/:asset", streamHandler.Stream)
+
+	hls := rg.Group("/hls", middleware.AuthMiddleware(ts))
+
+	hlsHandler := routes.NewHLSHandler(ts, cfg, movieRepo, playlistRepo, blobStore)
+
+	hls.GET("/:movie_id/master.m3u8", hlsHandler.Master)
+	hls.GET("/:movie_id/:rendition/index.m3u8", hlsHandler.Media)
+	hls.GET("/:movie_id/:rendition/:playlist_id/:segment", hlsHandler.Segment)
+	hls.GET("/:movie_id/subtitles/:lang/:playlist", hlsHandler.Subtitle)
+
+	playbackHandler := routes.NewPlaybackHandler(ts, cfg, signer, movieRepo, userRepo, playbackSessionRepo)
+
+	rg.POST("/movies/:id/playback", middleware.AuthMiddleware(ts), playbackHandler.Start)
+
+	sessions := rg.Group("/me/playback-sessions", middleware.AuthMiddleware(ts))
+	sessions.GET("", playbackHandler.ListSessions)
+	sessions.POST("/:session_id/heartbeat", playbackHandler.Heartbeat)
+	sessions.DELETE("/:session_id", playbackHandler.StopSession)
+
+	// The token is a path segment so relative playlist URIs carry it along
+	play := rg.Group("/play/:token")
+
+	videoScope := middleware.PlaybackAuth(signer, func(c *gin.Context) string {
+		return playbackservice.VideoScope(c.Param("asset"))
+	})
+	play.GET("/stream/:movie_id/:asset", videoScope, streamHandler.SignedStream)
+	play.HEAD("/stream/:movie_id/:asset", videoScope, streamHandler.SignedStream)
+
+	signedHLS := play.Group("/hls/:movie_id", middleware.PlaybackAuth(signer, func(*gin.Context) string {
+		return playbackservice.ScopeHLS
+	}))
+	signedHLS.GET("/master.m3u8", hlsHandler.Master)
+	signedHLS.GET("/:rendition/index.m3u8", hlsHandler.Media)
+	signedHLS.GET("/:rendition/:playlist_id/:segment", hlsHandler.Segment)
+	signedHLS.GET("/subtitles/:lang/:playlist", hlsHandler.Subtitle)
+}
+
+// setupAdminRoutes configures catalog maintenance routes (admin only)
+func setupAdminRoutes(rg *gin.RouterGroup, cfg *config.Config, ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, personRepo repositories.PersonRepository, revisionRepo repositories.MovieRevisionRepository, rankingRepo repositories.RankingRepository, watchlistRepo repositories.WatchlistRepository, blobStore storage.BlobStore, playlistRepo repositories.HLSPlaylistRepository, locales *utils.Locales) {
+	admin := rg.Group("/admin", middleware.AuthMiddleware(ts), middleware.AdminOnly())
+
+	movieHandler := routes.NewMovieHandler(ts, cfg, movieRepo, genreRepo, personRepo, revisionRepo, rankingRepo, watchlistRepo, blobStore, playlistRepo, locales)
+
+	admin.POST("/movies/import", movieHandler.Import)
+	admin.GET("/movies/export", movieHandler.Export)
+	admin.GET("/movies/trash", movieHandler.GetTrash)
+	admin.POST("/movies/:id/restore", movieHandler.Restore)
+}
+
+// startMovieTrashPurge periodically removes movies that have been in the trash
+// longer than the configured retention period, along with their reviews, their
+// watchlist and watch history entries, their places in curated collections and
+// their HLS playlists, and deletes their files from the blob store
+func startMovieTrashPurge(movieRepo repositories.MovieRepository, reviewRepo repositories.ReviewRepository, watchlistRepo repositories.WatchlistRepository, historyRepo repositories.WatchHistoryRepository, collectionRepo repositories.CollectionRepository, playlistRepo repositories.HLSPlaylistRepository, blobStore storage.BlobStore, cfg *config.Config) {
+	if cfg.MovieTrashRetentionDays <= 0 || cfg.MovieTrashPurgeIntervalMin <= 0 {
+		log.Println("Movie trash purge disabled")
+		return
+	}
+
+	retention := time.Duration(cfg.MovieTrashRetentionDays) * 24 * time.Hour
+	interval := time.Duration(cfg.MovieTrashPurgeIntervalMin) * time.Minute
+
+	purge := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		movies, err := movieRepo.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Println("Failed to purge movie trash:", err)
+			// Movies removed before the error are still cleaned up
+		}
+		if len(movies) == 0 {
+			return
+		}
+		log.Printf("Purged %d movies from trash", len(movies))
+
+		purged := make([]bson.ObjectID, len(movies))
+		for i, movie := range movies {
+			purged[i] = movie.ID
+		}
+
+		if _, err := reviewRepo.DeleteByMovies(ctx, purged); err != nil {
+			log.Println("Failed to remove reviews of purged movies:", err)
+		}
+		if _, err := watchlistRepo.DeleteByMovies(ctx, purged); err != nil {
+			log.Println("Failed to remove purged movies from watchlists:", err)
+		}
+		if _, err := historyRepo.DeleteByMovies(ctx, purged); err != nil {
+			log.Println("Failed to remove purged movies from watch history:", err)
+		}
+		if _, err := collectionRepo.RemoveMovies(ctx, purged); err != nil {
+			log.Println("Failed to remove purged movies from collections:", err)
+		}
+
+		purgeMovieBlobs(movies, purged, playlistRepo, blobStore)
+	}
+
+	go func() {
+		purge()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purge()
+		}
+	}()
+}
+
+// purgeMovieBlobs deletes the files of purged movies: poster variants, video
+// assets, subtitle tracks and the segments of every HLS playlist. Playlists
+// are only dropped afterwards, since they list the segment keys.
+func purgeMovieBlobs(movies []models.Movie, movieIDs []bson.ObjectID, playlistRepo repositories.HLSPlaylistRepository, blobStore storage.BlobStore) {
+	// A rendition can have thousands of segments
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	deleteBlob := func(key string) {
+		if err := blobStore.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrBlobNotFound) {
+			log.Printf("Failed to delete blob %s of a purged movie: %v", key, err)
+		}
+	}
+
+	for _, movie := range movies {
+		for _, key := range movie.BlobKeys() {
+			deleteBlob(key)
+		}
+	}
+
+	playlists, err := playlistRepo.FindByMovies(ctx, movieIDs)
+	if err != nil {
+		log.Println("Failed to load HLS playlists of purged movies:", err)
+		return
+	}
+	for _, playlist := range playlists {
+		for _, key := range playlist.BlobKeys() {
+			deleteBlob(key)
+		}
+	}
+
+	if _, err := playlistRepo.DeleteByMovies(ctx, movieIDs); err != nil {
+		log.Println("Failed to remove HLS playlists of purged movies:", err)
+	}
+}
@@ -46,6 +46,20 @@ func AuthMiddleware(ts *authservice.TokenService) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth identifies the caller on public routes that personalize their
+// response. A request without a valid access token continues anonymously.
+func OptionalAuth(ts *authservice.TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			if userID, err := ts.ValidateAccessToken(authHeader[7:]); err == nil {
+				c.Set("user_id", userID)
+			}
+		}
+		c.Next()
+	}
+}
+
 // AdminOnly middleware checks if user has admin role
 // Must be used after AuthMiddleware
 func AdminOnly() gin.HandlerFunc {
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const cacheControlKey = "cache_control"
+
+// CacheControl sets the Cache-Control policy of a cacheable route. The header is
+// only written by handlers on successful reads, so errors are never cached.
+func CacheControl(value string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cacheControlKey, value)
+		c.Next()
+	}
+}
+
+// GetCacheControl returns the Cache-Control policy configured for the route
+func GetCacheControl(c *gin.Context) string {
+	return c.GetString(cacheControlKey)
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const localeChainKey = "locale_chain"
+
+// Locale negotiates the response locale from the lang query parameter or the
+// Accept-Language header. Localized handlers write Content-Language themselves,
+// so responses that don't depend on the locale stay cacheable for everyone.
+func Locale(locales *utils.Locales) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(localeChainKey, locales.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// GetLocaleChain returns the negotiated fallback chain of the request, or nil
+// when the request was not negotiated and defaults should be used
+func GetLocaleChain(c *gin.Context) models.LocaleChain {
+	chain, _ := c.Get(localeChainKey)
+	locales, _ := chain.(models.LocaleChain)
+	return locales
+}
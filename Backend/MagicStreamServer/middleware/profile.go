@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	profileKey       = "profile"
+	maturityLimitKey = "maturity_limit"
+	planKey          = "plan"
+)
+
+// ActiveProfile loads the profile a signed-in caller is watching as, and with
+// it the profile's maturity limit and the account's plan. It runs on every API route, whichever auth
+// middleware the route uses, so the limit is known even on public catalog
+// routes. Requests without a valid access token continue without a profile;
+// the route's own auth decides whether they may.
+func ActiveProfile(ts *authservice.TokenService, userRepo repositories.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			if claims, err := ts.ParseAccessToken(authHeader[7:]); err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				user, err := userRepo.FindByID(ctx, claims.UserID)
+				cancel()
+
+				switch {
+				case err == nil:
+					profile := user.Profile(claims.ProfileID)
+					if profile == nil {
+						// Carrying on without the profile would drop its maturity limit
+						c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+							"error": "Profile no longer exists, sign in again",
+						})
+						return
+					}
+					c.Set(profileKey, profile)
+					c.Set(maturityLimitKey, profile.MaxCertification)
+					c.Set(planKey, user.EffectivePlan(time.Now()))
+				case !errors.Is(err, repositories.ErrUserNotFound):
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+						"error": "Failed to load profile",
+					})
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// GetProfile returns the active profile of a signed-in caller
+func GetProfile(c *gin.Context) (*models.Profile, bool) {
+	value, exists := c.Get(profileKey)
+	if !exists {
+		return nil, false
+	}
+	profile, ok := value.(*models.Profile)
+	return profile, ok
+}
+
+// GetProfileID returns the ID of the caller's active profile
+func GetProfileID(c *gin.Context) (bson.ObjectID, bool) {
+	profile, ok := GetProfile(c)
+	if !ok {
+		return bson.ObjectID{}, false
+	}
+	return profile.ID, true
+}
+
+// GetMaturityLimit returns the highest certification the caller may watch, or
+// an empty string when they have no limit
+func GetMaturityLimit(c *gin.Context) string {
+	return c.GetString(maturityLimitKey)
+}
+
+// GetPlan returns the plan the caller may watch; callers without a signed-in
+// account are on the free plan
+func GetPlan(c *gin.Context) string {
+	if plan := c.GetString(planKey); plan != "" {
+		return plan
+	}
+	return models.PlanFree
+}
+
+// ViewerContext returns a background context carrying the caller's maturity
+// limit. Handlers reading the catalog on behalf of a viewer derive their
+// context from it, and the movie repository hides movies above the limit.
+func ViewerContext(c *gin.Context) context.Context {
+	return repositories.WithMaturityLimit(context.Background(), GetMaturityLimit(c))
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0001_movie_metadata_defaults",
+		Description: "backfill extended metadata fields on existing movies",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			movies := db.Collection("movies")
+
+			defaults := []struct {
+				field string
+				value interface{}
+			}{
+				{"release_date", ""},
+				{"runtime", 0},
+				{"synopsis", ""},
+				{"original_language", ""},
+				{"spoken_languages", []string{}},
+				{"subtitle_languages", []string{}},
+				{"country", ""},
+				{"age_certification", models.CertificationNR},
+				{"directors", bson.A{}},
+				{"cast", bson.A{}},
+			}
+
+			for _, d := range defaults {
+				if err := setDefaultWhereMissing(ctx, movies, d.field, d.value); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
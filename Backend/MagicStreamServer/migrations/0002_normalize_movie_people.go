@@ -0,0 +1,97 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// legacyCredits holds the free-text cast and crew fields movies carried before people existed
+type legacyCredits struct {
+	ID        bson.ObjectID `bson:"_id"`
+	Directors []string      `bson:"directors"`
+	Cast      []struct {
+		Name      string `bson:"name"`
+		Character string `bson:"character"`
+	} `bson:"cast"`
+}
+
+func init() {
+	register(Migration{
+		ID:          "0002_normalize_movie_people",
+		Description: "move movie directors and cast into the people collection",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			movies := db.Collection("movies")
+			people := db.Collection("people")
+
+			cursor, err := movies.Find(ctx, bson.M{"credits": bson.M{"$exists": false}})
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+
+			for cursor.Next(ctx) {
+				var legacy legacyCredits
+				if err := cursor.Decode(&legacy); err != nil {
+					return err
+				}
+
+				credits := []models.Credit{}
+				for _, name := range legacy.Directors {
+					personID, err := findOrCreatePerson(ctx, people, name, "Directing")
+					if err != nil {
+						return err
+					}
+					credits = append(credits, models.Credit{PersonID: personID, Name: name, Job: models.JobDirector, Order: len(credits)})
+				}
+				for _, member := range legacy.Cast {
+					personID, err := findOrCreatePerson(ctx, people, member.Name, "Acting")
+					if err != nil {
+						return err
+					}
+					credits = append(credits, models.Credit{PersonID: personID, Name: member.Name, Job: models.JobActor, Character: member.Character, Order: len(credits)})
+				}
+
+				update := bson.M{
+					"$set":   bson.M{"credits": credits},
+					"$unset": bson.M{"directors": "", "cast": ""},
+				}
+				if _, err := movies.UpdateByID(ctx, legacy.ID, update); err != nil {
+					return err
+				}
+			}
+
+			return cursor.Err()
+		},
+	})
+}
+
+// findOrCreatePerson returns the ID of the person with the exact given name, creating them if needed
+func findOrCreatePerson(ctx context.Context, people *mongo.Collection, name, department string) (bson.ObjectID, error) {
+	var existing models.Person
+	err := people.FindOne(ctx, bson.M{"name": name}).Decode(&existing)
+	if err == nil {
+		return existing.ID, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return bson.NilObjectID, err
+	}
+
+	now := time.Now()
+	person := models.Person{
+		ID:                 bson.NewObjectID(),
+		Name:               name,
+		KnownForDepartment: department,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if _, err := people.InsertOne(ctx, person); err != nil {
+		return bson.NilObjectID, err
+	}
+
+	return person.ID, nil
+}
@@ -0,0 +1,17 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0003_movie_versions",
+		Description: "start existing movies at version 1 for optimistic concurrency",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return setDefaultWhereMissing(ctx, db.Collection("movies"), "version", int64(1))
+		},
+	})
+}
@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0004_movie_updated_at",
+		Description: "stamp existing movies with updated_at for Last-Modified headers",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return setDefaultWhereMissing(ctx, db.Collection("movies"), "updated_at", time.Now())
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0005_rankings_catalog",
+		Description: "seed the rankings catalog and copy ranking order onto movies",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			rankings := db.Collection("rankings")
+			movies := db.Collection("movies")
+
+			// Same levels as data-seed/rankings.json, best first; unranked sorts last
+			defaults := []struct {
+				value int
+				name  string
+				order int
+			}{
+				{1, "Excellent", 1},
+				{2, "Good", 2},
+				{3, "Okay", 3},
+				{4, "Bad", 4},
+				{5, "Terrible", 5},
+				{999, "Not_Ranked", 6},
+			}
+
+			for _, d := range defaults {
+				_, err := rankings.UpdateOne(ctx,
+					bson.M{"ranking_value": d.value},
+					bson.M{"$setOnInsert": bson.M{"ranking_value": d.value, "ranking_name": d.name, "order": d.order}},
+					options.UpdateOne().SetUpsert(true),
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, err := rankings.Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "ranking_value", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			})
+			if err != nil {
+				return err
+			}
+
+			cursor, err := rankings.Find(ctx, bson.M{})
+			if err != nil {
+				return err
+			}
+			var catalog []struct {
+				Value int    `bson:"ranking_value"`
+				Name  string `bson:"ranking_name"`
+				Order int    `bson:"order"`
+			}
+			if err := cursor.All(ctx, &catalog); err != nil {
+				return err
+			}
+
+			// Movies change shape, so bump their versions to invalidate cached copies
+			touch := func(set bson.M) bson.M {
+				return bson.M{"$set": set, "$inc": bson.M{"version": 1}, "$currentDate": bson.M{"updated_at": true}}
+			}
+
+			known := bson.A{}
+			unranked := bson.M{}
+			for _, ranking := range catalog {
+				known = append(known, ranking.Value)
+				if ranking.Value == 999 {
+					unranked = bson.M{"ranking_value": ranking.Value, "ranking_name": ranking.Name, "order": ranking.Order}
+				}
+				_, err := movies.UpdateMany(ctx,
+					bson.M{"ranking.ranking_value": ranking.Value},
+					touch(bson.M{"ranking.ranking_name": ranking.Name, "ranking.order": ranking.Order}),
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Movies with a value outside the catalog become unranked
+			_, err = movies.UpdateMany(ctx,
+				bson.M{"ranking.ranking_value": bson.M{"$nin": known}},
+				touch(bson.M{"ranking": unranked}),
+			)
+			return err
+		},
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0006_movie_user_ratings",
+		Description: "add empty rating summaries to movies and index reviews",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := setDefaultWhereMissing(ctx, db.Collection("movies"), "user_rating", models.NewRatingSummary()); err != nil {
+				return err
+			}
+
+			_, err := db.Collection("reviews").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					// One review per user and movie
+					Keys:    bson.D{{Key: "movie_id", Value: 1}, {Key: "user_id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "helpful_count", Value: -1}, {Key: "created_at", Value: -1}}},
+				{Keys: bson.D{{Key: "movie_id", Value: 1}, {Key: "created_at", Value: -1}}},
+			})
+			return err
+		},
+	})
+}
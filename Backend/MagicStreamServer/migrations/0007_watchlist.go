@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0007_watchlist",
+		Description: "index the watchlist collection",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("watchlist").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					// A movie is on a user's watchlist at most once
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "movie_id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "position", Value: 1}}},
+				// Cleanup when a movie is purged
+				{Keys: bson.D{{Key: "movie_id", Value: 1}}},
+			})
+			return err
+		},
+	})
+}
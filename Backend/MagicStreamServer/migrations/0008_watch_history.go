@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0008_watch_history",
+		Description: "index the watch_history collection",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("watch_history").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					// One entry per user and movie; progress coalescing relies on it
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "movie_id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_watched_at", Value: -1}}},
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "in_progress", Value: 1}, {Key: "last_watched_at", Value: -1}}},
+				// Cleanup when a movie is purged
+				{Keys: bson.D{{Key: "movie_id", Value: 1}}},
+			})
+			return err
+		},
+	})
+}
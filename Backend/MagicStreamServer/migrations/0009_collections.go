@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0009_collections",
+		Description: "index the collections collection",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("collections").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "position", Value: 1}, {Key: "name", Value: 1}}},
+				// Cleanup when a movie is purged
+				{Keys: bson.D{{Key: "movie_ids", Value: 1}}},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0010_hls_playlists",
+		Description: "index the hls_playlists collection",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Cleanup when a movie is purged
+			_, err := db.Collection("hls_playlists").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "movie_id", Value: 1}},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0011_playback_sessions",
+		Description: "index the playback_sessions collection and expire stale sessions",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("playback_sessions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				// Active stream counting per user
+				{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "expires_at", Value: 1}}},
+				// Sessions whose heartbeats stopped are removed by MongoDB
+				{
+					Keys:    bson.D{{Key: "expires_at", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(0),
+				},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0012_movie_search",
+		Description: "build the multi-locale search index of movies",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			movies := db.Collection("movies")
+
+			cursor, err := movies.Find(ctx, bson.M{})
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+
+			// search is not part of the API, so versions stay as they are
+			for cursor.Next(ctx) {
+				var movie models.Movie
+				if err := cursor.Decode(&movie); err != nil {
+					return err
+				}
+				_, err := movies.UpdateOne(ctx,
+					bson.M{"_id": movie.ID},
+					bson.M{"$set": bson.M{"search": models.NewMovieSearchIndex(&movie)}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			if err := cursor.Err(); err != nil {
+				return err
+			}
+
+			_, err = movies.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				// Titles and synopses come in several languages, so no stemming
+				{
+					Keys: bson.D{{Key: "search.titles", Value: "text"}, {Key: "search.synopses", Value: "text"}},
+					Options: options.Index().
+						SetName("movie_search").
+						SetDefaultLanguage("none").
+						SetWeights(bson.D{{Key: "search.titles", Value: 10}, {Key: "search.synopses", Value: 1}}),
+				},
+				// Anchored prefix regexes for autocomplete
+				{Keys: bson.D{{Key: "search.prefixes", Value: 1}}},
+			})
+			return err
+		},
+	})
+}
@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// userBeforeProfiles holds the per-viewer fields that lived on the user
+type userBeforeProfiles struct {
+	UserID           string         `bson:"user_id"`
+	FirstName        string         `bson:"first_name"`
+	FavouriteGenres  []models.Genre `bson:"favourite_genres"`
+	ParentalControls *struct {
+		PINHash          string `bson:"pin_hash"`
+		MaxCertification string `bson:"max_certification"`
+	} `bson:"parental_controls"`
+}
+
+func init() {
+	register(Migration{
+		ID:          "0013_profiles",
+		Description: "move favourite genres, maturity limits, watchlists and watch history to a default profile per user",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			users := db.Collection("users")
+			watchlist := db.Collection("watchlist")
+			history := db.Collection("watch_history")
+
+			cursor, err := users.Find(ctx, bson.M{"profiles.0": bson.M{"$exists": false}})
+			if err != nil {
+				return err
+			}
+			defer cursor.Close(ctx)
+
+			for cursor.Next(ctx) {
+				var user userBeforeProfiles
+				if err := cursor.Decode(&user); err != nil {
+					return err
+				}
+
+				now := time.Now()
+				profile := models.Profile{
+					ID:              bson.NewObjectID(),
+					Name:            user.FirstName,
+					FavouriteGenres: user.FavouriteGenres,
+					CreatedAt:       now,
+					UpdatedAt:       now,
+				}
+				if profile.FavouriteGenres == nil {
+					profile.FavouriteGenres = []models.Genre{}
+				}
+
+				unset := bson.M{"favourite_genres": ""}
+				if controls := user.ParentalControls; controls != nil {
+					profile.MaxCertification = controls.MaxCertification
+					if controls.PINHash == "" {
+						unset["parental_controls"] = ""
+					} else {
+						unset["parental_controls.max_certification"] = ""
+					}
+				}
+
+				// The entries move first and the profile is set last, so a user
+				// is only skipped on a rerun once their entries are moved. Until
+				// then every entry of the user belongs to the default profile,
+				// including any moved to a profile ID from an earlier failed run.
+				backfill := bson.M{"user_id": user.UserID}
+				set := bson.M{"$set": bson.M{"profile_id": profile.ID}}
+				if _, err := watchlist.UpdateMany(ctx, backfill, set); err != nil {
+					return err
+				}
+				if _, err := history.UpdateMany(ctx, backfill, set); err != nil {
+					return err
+				}
+
+				_, err := users.UpdateOne(ctx,
+					bson.M{"user_id": user.UserID},
+					bson.M{"$set": bson.M{"profiles": []models.Profile{profile}}, "$unset": unset},
+				)
+				if err != nil {
+					return err
+				}
+			}
+			if err := cursor.Err(); err != nil {
+				return err
+			}
+
+			// Entries of users that no longer exist can't be reached any more
+			// and would clash on the new unique indexes
+			orphans := bson.M{"profile_id": bson.M{"$exists": false}}
+			if _, err := watchlist.DeleteMany(ctx, orphans); err != nil {
+				return err
+			}
+			if _, err := history.DeleteMany(ctx, orphans); err != nil {
+				return err
+			}
+
+			for _, name := range []string{"user_id_1_movie_id_1", "user_id_1_position_1"} {
+				if err := dropIndexIfExists(ctx, watchlist, name); err != nil {
+					return err
+				}
+			}
+			for _, name := range []string{"user_id_1_movie_id_1", "user_id_1_last_watched_at_-1", "user_id_1_in_progress_1_last_watched_at_-1"} {
+				if err := dropIndexIfExists(ctx, history, name); err != nil {
+					return err
+				}
+			}
+
+			_, err = watchlist.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					// A movie is on a profile's watchlist at most once
+					Keys:    bson.D{{Key: "profile_id", Value: 1}, {Key: "movie_id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "profile_id", Value: 1}, {Key: "position", Value: 1}}},
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = history.Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					// One entry per profile and movie; progress coalescing relies on it
+					Keys:    bson.D{{Key: "profile_id", Value: 1}, {Key: "movie_id", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "profile_id", Value: 1}, {Key: "last_watched_at", Value: -1}}},
+				{Keys: bson.D{{Key: "profile_id", Value: 1}, {Key: "in_progress", Value: 1}, {Key: "last_watched_at", Value: -1}}},
+			})
+			return err
+		},
+	})
+}
+
+// dropIndexIfExists drops an index by name, ignoring indexes and collections
+// that are already gone
+func dropIndexIfExists(ctx context.Context, coll *mongo.Collection, name string) error {
+	err := coll.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// paymentEventRetention is how long processed webhook events are kept for
+// spotting redeliveries, well past how long providers keep retrying
+const paymentEventRetention = 30 * 24 * 60 * 60
+
+func init() {
+	register(Migration{
+		ID:          "0014_subscriptions",
+		Description: "make existing movies free to watch and expire processed payment events",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := setDefaultWhereMissing(ctx, db.Collection("movies"), "min_plan", models.PlanFree); err != nil {
+				return err
+			}
+
+			_, err := db.Collection("payment_events").Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "processed_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(paymentEventRetention),
+			})
+			return err
+		},
+	})
+}
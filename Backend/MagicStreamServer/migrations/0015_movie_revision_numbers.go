@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func init() {
+	register(Migration{
+		ID:          "0015_movie_revision_numbers",
+		Description: "renumber duplicate movie revisions and make revision numbers unique per movie",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			coll := db.Collection("movie_revisions")
+			if err := renumberDuplicateRevisions(ctx, coll); err != nil {
+				return err
+			}
+
+			_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+				// Concurrent writes to a movie can't take the same number
+				Keys:    bson.D{{Key: "movie_id", Value: 1}, {Key: "revision", Value: -1}},
+				Options: options.Index().SetUnique(true),
+			})
+			return err
+		},
+	})
+}
+
+// renumberDuplicateRevisions moves every revision sharing its number with an
+// older one of the same movie past the movie's latest revision, oldest first
+func renumberDuplicateRevisions(ctx context.Context, coll *mongo.Collection) error {
+	cursor, err := coll.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"movie_id": "$movie_id", "revision": "$revision"},
+			"ids": bson.M{"$push": "$_id"},
+		}}},
+		{{Key: "$match", Value: bson.M{"ids.1": bson.M{"$exists": true}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.revision", Value: 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var duplicates []struct {
+		Key struct {
+			MovieID bson.ObjectID `bson:"movie_id"`
+		} `bson:"_id"`
+		IDs []bson.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+
+	latest := map[bson.ObjectID]int{}
+	for _, group := range duplicates {
+		movieID := group.Key.MovieID
+		if _, ok := latest[movieID]; !ok {
+			var last struct {
+				Revision int `bson:"revision"`
+			}
+			opts := options.FindOne().SetSort(bson.M{"revision": -1})
+			if err := coll.FindOne(ctx, bson.M{"movie_id": movieID}, opts).Decode(&last); err != nil {
+				return err
+			}
+			latest[movieID] = last.Revision
+		}
+
+		// The oldest keeps its number
+		for _, id := range group.IDs[1:] {
+			latest[movieID]++
+			if _, err := coll.UpdateByID(ctx, id, bson.M{"$set": bson.M{"revision": latest[movieID]}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
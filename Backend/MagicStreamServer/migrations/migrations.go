@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Migration is a one-off data change that is applied once and recorded
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// appliedMigration is the record stored in the migrations collection
+type appliedMigration struct {
+	ID        string    `bson:"_id"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+var registry []Migration
+
+// register adds a migration to the registry, called from each migration file's init
+func register(m Migration) {
+	registry = append(registry, m)
+}
+
+// Run applies every pending migration in ID order
+func Run(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection("migrations")
+
+	pending := make([]Migration, len(registry))
+	copy(pending, registry)
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	for _, m := range pending {
+		count, err := coll.CountDocuments(ctx, bson.M{"_id": m.ID})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := m.Up(ctx, db); err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+
+		if _, err := coll.InsertOne(ctx, appliedMigration{ID: m.ID, AppliedAt: time.Now()}); err != nil {
+			return err
+		}
+		fmt.Printf("Applied migration %s: %s\n", m.ID, m.Description)
+	}
+
+	return nil
+}
+
+// setDefaultWhereMissing sets field to value on every document that lacks it
+func setDefaultWhereMissing(ctx context.Context, coll *mongo.Collection, field string, value interface{}) error {
+	filter := bson.M{field: bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{field: value}}
+	_, err := coll.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Collection kinds: a hand-picked list of movies or a saved movie query
+const (
+	CollectionKindManual = "manual"
+	CollectionKindQuery  = "query"
+)
+
+// MovieCollection is an admin-curated, named row of movies such as "Staff Picks".
+// Manual collections list their movies in order; query collections store GET
+// /movies query parameters and are evaluated when read.
+type MovieCollection struct {
+	ID          bson.ObjectID   `bson:"_id,omitempty" json:"_id,omitempty" example:"665f1c2e8a1b2c3d4e5f6a7b"`
+	Name        string          `bson:"name" json:"name" example:"Staff Picks"`
+	Description string          `bson:"description" json:"description" example:"Our favourites this month"`
+	Kind        string          `bson:"kind" json:"kind" example:"manual"`
+	MovieIDs    []bson.ObjectID `bson:"movie_ids,omitempty" json:"movie_ids,omitempty"`
+	Query       string          `bson:"query,omitempty" json:"query,omitempty" example:"genre=Thriller&year_from=1990&year_to=1999&sort=rating"`
+	Position    int             `bson:"position" json:"position" example:"0"`
+	CreatedAt   time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+// MovieCollectionRequest for creating or replacing a collection. Manual
+// collections take movie_ids, query collections take query.
+type MovieCollectionRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=100" example:"Staff Picks"`
+	Description string   `json:"description" binding:"omitempty,max=500" example:"Our favourites this month"`
+	Kind        string   `json:"kind" binding:"required,oneof=manual query" example:"manual"`
+	MovieIDs    []string `json:"movie_ids" binding:"required_if=Kind manual,excluded_if=Kind query,max=500,dive,mongodb" example:"507f1f77bcf86cd799439011"`
+	Query       string   `json:"query" binding:"required_if=Kind query,excluded_if=Kind manual,max=1000" example:"genre=Thriller&year_from=1990&year_to=1999&sort=rating"`
+	Position    int      `json:"position" binding:"min=0" example:"0"`
+}
+
+// ToCollection converts the request to a collection; movie IDs are already validated
+func (req *MovieCollectionRequest) ToCollection() MovieCollection {
+	collection := MovieCollection{
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        req.Kind,
+		Query:       req.Query,
+		Position:    req.Position,
+	}
+	for _, id := range req.MovieIDs {
+		objectID, _ := bson.ObjectIDFromHex(id)
+		collection.MovieIDs = append(collection.MovieIDs, objectID)
+	}
+	return collection
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchHistory is where a profile is in a movie. There is one entry per profile
+// and movie; progress reports update it in place.
+type WatchHistory struct {
+	ID              bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID          string        `bson:"user_id" json:"-"`
+	ProfileID       bson.ObjectID `bson:"profile_id" json:"-"`
+	MovieID         bson.ObjectID `bson:"movie_id" json:"movie_id" example:"507f1f77bcf86cd799439011"`
+	PositionSeconds int           `bson:"position_seconds" json:"position_seconds" example:"3120"`
+	DurationSeconds int           `bson:"duration_seconds" json:"duration_seconds" example:"8520"`
+	Progress        float64       `bson:"progress" json:"progress" example:"0.366"`
+	Device          string        `bson:"device" json:"device" example:"living-room-tv"`
+	InProgress      bool          `bson:"in_progress" json:"in_progress" example:"true"`
+	Watched         bool          `bson:"watched" json:"watched" example:"false"`
+	WatchCount      int           `bson:"watch_count" json:"watch_count" example:"0"`
+	FirstWatchedAt  time.Time     `bson:"first_watched_at" json:"first_watched_at"`
+	LastWatchedAt   time.Time     `bson:"last_watched_at" json:"last_watched_at"`
+	LastCompletedAt *time.Time    `bson:"last_completed_at,omitempty" json:"last_completed_at,omitempty"`
+}
+
+// WatchHistoryItem is a history entry with its movie, as returned to the user
+type WatchHistoryItem struct {
+	WatchHistory
+	Movie Movie `json:"movie"`
+}
+
+// WatchProgressRequest is a playback progress report sent periodically by a player
+type WatchProgressRequest struct {
+	MovieID         string `json:"movie_id" binding:"required" example:"507f1f77bcf86cd799439011"`
+	PositionSeconds int    `json:"position_seconds" binding:"min=0" example:"3120"`
+	DurationSeconds int    `json:"duration_seconds" binding:"required,min=1" example:"8520"`
+	Device          string `json:"device" binding:"omitempty,max=100" example:"living-room-tv"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HLSRendition describes one quality level of a movie's HLS stream. It is kept
+// on the movie so clients can see the available qualities; the segment list
+// lives in a separate HLSPlaylist to keep movie documents small.
+type HLSRendition struct {
+	PlaylistID       bson.ObjectID `bson:"playlist_id" json:"-"`
+	Bandwidth        int           `bson:"bandwidth" json:"bandwidth" example:"5000000"`
+	AverageBandwidth int           `bson:"average_bandwidth,omitempty" json:"average_bandwidth,omitempty" example:"4200000"`
+	Width            int           `bson:"width,omitempty" json:"width,omitempty" example:"1920"`
+	Height           int           `bson:"height,omitempty" json:"height,omitempty" example:"1080"`
+	Codecs           string        `bson:"codecs,omitempty" json:"codecs,omitempty" example:"avc1.640028,mp4a.40.2"`
+	FrameRate        float64       `bson:"frame_rate,omitempty" json:"frame_rate,omitempty" example:"23.976"`
+	Duration         float64       `bson:"duration" json:"duration" example:"8520.5"`
+	SegmentCount     int           `bson:"segment_count" json:"segment_count" example:"1420"`
+	UploadedAt       time.Time     `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// HLSRenditionRequest holds the form fields sent with a rendition upload
+type HLSRenditionRequest struct {
+	Bandwidth        int     `form:"bandwidth" binding:"required,min=1" example:"5000000"`
+	AverageBandwidth int     `form:"average_bandwidth" binding:"omitempty,min=1" example:"4200000"`
+	Resolution       string  `form:"resolution" binding:"required,max=20" example:"1920x1080"`
+	Codecs           string  `form:"codecs" binding:"omitempty,max=200" example:"avc1.640028,mp4a.40.2"`
+	FrameRate        float64 `form:"frame_rate" binding:"omitempty,min=1,max=300" example:"23.976"`
+}
+
+// HLSPlaylist is the segment list of one uploaded rendition. Every upload gets
+// its own playlist and blob prefix, so replacing a rendition never changes
+// segments a player is still fetching.
+type HLSPlaylist struct {
+	ID             bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	MovieID        bson.ObjectID `bson:"movie_id" json:"movie_id"`
+	Rendition      string        `bson:"rendition" json:"rendition"`
+	KeyPrefix      string        `bson:"key_prefix" json:"-"`
+	TargetDuration int           `bson:"target_duration" json:"target_duration"`
+	InitSegment    string        `bson:"init_segment,omitempty" json:"init_segment,omitempty"`
+	Segments       []HLSSegment  `bson:"segments" json:"segments"`
+	CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// BlobKeys returns the keys of the init segment and every media segment
+func (p *HLSPlaylist) BlobKeys() []string {
+	keys := make([]string, 0, len(p.Segments)+1)
+	if p.InitSegment != "" {
+		keys = append(keys, p.KeyPrefix+"/"+p.InitSegment)
+	}
+	for _, segment := range p.Segments {
+		keys = append(keys, p.KeyPrefix+"/"+segment.URI)
+	}
+	return keys
+}
+
+// HLSSegment is one media segment of a rendition, stored as KeyPrefix/URI
+type HLSSegment struct {
+	URI      string  `bson:"uri" json:"uri" example:"segment00001.ts"`
+	Duration float64 `bson:"duration" json:"duration" example:"6.006"`
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// LocaleChain lists the locales a response looks up translated text in, most
+// preferred first. The last locale is the catalog default, whose text lives in
+// the untranslated fields.
+type LocaleChain []string
+
+// Primary is the locale the response is written in
+func (chain LocaleChain) Primary() string {
+	if len(chain) == 0 {
+		return ""
+	}
+	return chain[0]
+}
+
+// pick returns the first non-empty translation along the chain, falling back to
+// the default text once the default locale is reached
+func (chain LocaleChain) pick(base string, translated func(locale string) string) string {
+	for i, locale := range chain {
+		if i == len(chain)-1 {
+			break
+		}
+		if text := translated(locale); text != "" {
+			return text
+		}
+	}
+	return base
+}
+
+// MovieTranslation holds the translated text of a movie in one locale. Empty
+// fields fall back along the locale chain.
+type MovieTranslation struct {
+	Title       string `bson:"title,omitempty" json:"title,omitempty" binding:"omitempty,min=2,max=500" example:"Penebusan Shawshank"`
+	Synopsis    string `bson:"synopsis,omitempty" json:"synopsis,omitempty" binding:"omitempty,max=5000" example:"Dua narapidana menjalin persahabatan selama bertahun-tahun."`
+	AdminReview string `bson:"admin_review,omitempty" json:"admin_review,omitempty" binding:"omitempty,max=1000" example:"Salah satu film terbaik sepanjang masa"`
+}
+
+// GenreTranslationsRequest replaces the translated names of a genre
+type GenreTranslationsRequest struct {
+	Translations map[string]string `json:"translations" binding:"required,dive,keys,bcp47_language_tag,endkeys,min=2,max=100"`
+}
+
+// Localize rewrites the title, synopsis, admin review and genre names of the
+// movie in the first locale of the chain that has them
+func (m *Movie) Localize(chain LocaleChain) {
+	if len(chain) < 2 {
+		return
+	}
+
+	m.Title = chain.pick(m.Title, func(locale string) string { return m.Translations[locale].Title })
+	m.Synopsis = chain.pick(m.Synopsis, func(locale string) string { return m.Translations[locale].Synopsis })
+	m.AdminReview = chain.pick(m.AdminReview, func(locale string) string { return m.Translations[locale].AdminReview })
+	for i := range m.Genre {
+		m.Genre[i].Localize(chain)
+	}
+}
+
+// Localize rewrites the genre name in the first locale of the chain that has one
+func (g *Genre) Localize(chain LocaleChain) {
+	if len(chain) < 2 {
+		return
+	}
+	g.GenreName = chain.pick(g.GenreName, func(locale string) string { return g.Translations[locale] })
+}
+
+// MovieSearchIndex is the text of a movie in every locale, kept on the movie so
+// search and autocomplete match whatever language the caller types in
+type MovieSearchIndex struct {
+	Titles   []string `bson:"titles"`   // text indexed
+	Synopses []string `bson:"synopses"` // text indexed
+	Prefixes []string `bson:"prefixes"` // folded title tails starting at each word, for autocomplete
+}
+
+// NewMovieSearchIndex collects the default and translated text of a movie
+func NewMovieSearchIndex(movie *Movie) MovieSearchIndex {
+	titles := []string{movie.Title}
+	synopses := []string{}
+	if movie.Synopsis != "" {
+		synopses = append(synopses, movie.Synopsis)
+	}
+
+	locales := make([]string, 0, len(movie.Translations))
+	for locale := range movie.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		translation := movie.Translations[locale]
+		if translation.Title != "" {
+			titles = appendUnique(titles, translation.Title)
+		}
+		if translation.Synopsis != "" {
+			synopses = appendUnique(synopses, translation.Synopsis)
+		}
+	}
+
+	prefixes := []string{}
+	for _, title := range titles {
+		words := strings.Fields(FoldSearchText(title))
+		for i := range words {
+			prefixes = appendUnique(prefixes, strings.Join(words[i:], " "))
+		}
+	}
+
+	return MovieSearchIndex{Titles: titles, Synopses: synopses, Prefixes: prefixes}
+}
+
+// FoldSearchText lowercases text, strips accents and turns punctuation into
+// spaces so typed queries compare with the stored autocomplete prefixes
+func FoldSearchText(text string) string {
+	// Transformers keep state, so each call gets its own chain. Combining marks
+	// are dropped after decomposition, so "Amélie" folds like "Amelie".
+	stripMarks := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripMarks, text)
+	if err != nil {
+		folded = text
+	}
+
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, folded)
+
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// MovieSuggestion is one autocomplete result
+type MovieSuggestion struct {
+	ID           bson.ObjectID `json:"_id" example:"507f1f77bcf86cd799439011"`
+	Title        string        `json:"title" example:"The Shawshank Redemption"`
+	MatchedTitle string        `json:"matched_title,omitempty" example:"Penebusan Shawshank"` // set when the query matched a title in another locale
+	ReleaseDate  string        `json:"release_date,omitempty" example:"1994-09-23"`
+	PosterPath   string        `json:"poster_path,omitempty" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
+}
@@ -1,55 +1,162 @@
 package models
 
 import (
+	"strconv"
+	"time"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // Genre represents a movie genre
 type Genre struct {
-	GenreID   int    `bson:"genre_id" json:"genre_id" binding:"required" example:"1"`
-	GenreName string `bson:"genre_name" json:"genre_name" binding:"required,min=2,max=100" example:"Action"`
+	GenreID      int               `bson:"genre_id" json:"genre_id" binding:"required" example:"1"`
+	GenreName    string            `bson:"genre_name" json:"genre_name" binding:"required,min=2,max=100" example:"Action"`
+	Translations map[string]string `bson:"translations,omitempty" json:"translations,omitempty"` // genre name keyed by locale, copied from the catalog
 }
 
-// Ranking represents movie ranking information
-type Ranking struct {
-	RankingValue int    `bson:"ranking_value" json:"ranking_value" binding:"required,min=1,max=10" example:"9"`
-	RankingName  string `bson:"ranking_name" json:"ranking_name" binding:"required,min=2,max=50" example:"Masterpiece"`
+// Age certifications accepted on movies
+const (
+	CertificationG    = "G"
+	CertificationPG   = "PG"
+	CertificationPG13 = "PG-13"
+	CertificationR    = "R"
+	CertificationNC17 = "NC-17"
+	CertificationNR   = "NR" // not rated
+)
+
+// certificationOrder ranks certifications from the youngest audience up.
+// Unrated movies come last, so every maturity limit hides them.
+var certificationOrder = []string{CertificationG, CertificationPG, CertificationPG13, CertificationR, CertificationNC17, CertificationNR}
+
+// CertificationsUpTo returns the certifications a viewer limited to max may
+// watch, or nil when max is not a certification
+func CertificationsUpTo(max string) []string {
+	for i, certification := range certificationOrder {
+		if certification == max {
+			return append([]string(nil), certificationOrder[:i+1]...)
+		}
+	}
+	return nil
 }
 
 // Movie represents a movie document in the database
 type Movie struct {
-	ID          bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty" example:"507f1f77bcf86cd799439011"`
-	ImdbID      string        `bson:"imdb_id" json:"imdb_id" binding:"required,min=9,max=10" example:"tt0111161"`
-	Title       string        `bson:"title" json:"title" binding:"required,min=2,max=500" example:"The Shawshank Redemption"`
-	PosterPath  string        `bson:"poster_path" json:"poster_path" binding:"required,url" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
-	YouTubeID   string        `bson:"youtube_id" json:"youtube_id" binding:"required,min=11,max=11" example:"6hB3S9bIaco"`
-	Genre       []Genre       `bson:"genre" json:"genre" binding:"required,min=1,dive"`
-	AdminReview string        `bson:"admin_review" json:"admin_review" binding:"omitempty,max=1000" example:"One of the greatest movies of all time"`
-	Ranking     Ranking       `bson:"ranking" json:"ranking" binding:"required"`
+	ID                bson.ObjectID               `bson:"_id,omitempty" json:"_id,omitempty" example:"507f1f77bcf86cd799439011"`
+	ImdbID            string                      `bson:"imdb_id" json:"imdb_id" binding:"required,min=9,max=10" example:"tt0111161"`
+	Title             string                      `bson:"title" json:"title" binding:"required,min=2,max=500" example:"The Shawshank Redemption"`
+	PosterPath        string                      `bson:"poster_path" json:"poster_path" binding:"omitempty,url" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
+	YouTubeID         string                      `bson:"youtube_id" json:"youtube_id" binding:"required,min=11,max=11" example:"6hB3S9bIaco"`
+	Genre             []Genre                     `bson:"genre" json:"genre" binding:"required,min=1,dive"`
+	AdminReview       string                      `bson:"admin_review" json:"admin_review" binding:"omitempty,max=1000" example:"One of the greatest movies of all time"`
+	Ranking           Ranking                     `bson:"ranking" json:"ranking" binding:"required"`
+	ReleaseDate       string                      `bson:"release_date" json:"release_date" binding:"omitempty,datetime=2006-01-02" example:"1994-09-23"`
+	Runtime           int                         `bson:"runtime" json:"runtime" binding:"omitempty,min=1,max=1440" example:"142"`
+	Synopsis          string                      `bson:"synopsis" json:"synopsis" binding:"omitempty,max=5000" example:"Two imprisoned men bond over a number of years."`
+	OriginalLanguage  string                      `bson:"original_language" json:"original_language" binding:"omitempty,bcp47_language_tag" example:"en"`
+	SpokenLanguages   []string                    `bson:"spoken_languages" json:"spoken_languages" binding:"omitempty,dive,bcp47_language_tag" example:"en"`
+	SubtitleLanguages []string                    `bson:"subtitle_languages" json:"subtitle_languages" binding:"omitempty,dive,bcp47_language_tag" example:"id"`
+	Country           string                      `bson:"country" json:"country" binding:"omitempty,iso3166_1_alpha2" example:"US"`
+	AgeCertification  string                      `bson:"age_certification" json:"age_certification" binding:"omitempty,oneof=G PG PG-13 R NC-17 NR" example:"R"`
+	MinPlan           string                      `bson:"min_plan" json:"min_plan" binding:"omitempty,oneof=free basic premium" example:"basic"` // cheapest plan that may play it
+	Credits           []Credit                    `bson:"credits" json:"credits" binding:"omitempty,dive"`
+	UserRating        RatingSummary               `bson:"user_rating" json:"user_rating"`
+	Poster            *PosterImage                `bson:"poster,omitempty" json:"poster,omitempty"`             // set when the poster was uploaded
+	Videos            map[string]VideoAsset       `bson:"videos,omitempty" json:"videos,omitempty"`             // keyed by asset name
+	Renditions        map[string]HLSRendition     `bson:"renditions,omitempty" json:"renditions,omitempty"`     // HLS qualities keyed by rendition name
+	Subtitles         []SubtitleTrack             `bson:"subtitles,omitempty" json:"subtitles,omitempty"`       // uploaded subtitle and caption tracks
+	Translations      map[string]MovieTranslation `bson:"translations,omitempty" json:"translations,omitempty"` // keyed by locale, the default locale lives in the fields above
+	Search            MovieSearchIndex            `bson:"search" json:"-"`
+	Version           int64                       `bson:"version" json:"version" example:"3"`
+	UpdatedAt         time.Time                   `bson:"updated_at" json:"updated_at"`
+	DeletedAt         *time.Time                  `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	DeletedBy         string                      `bson:"deleted_by,omitempty" json:"deleted_by,omitempty" example:"68385b9981097c6b4042dab4"`
+	InWatchlist       *bool                       `bson:"-" json:"in_watchlist,omitempty"` // only set for signed-in callers
+}
+
+// PosterImage describes an uploaded poster and its resized variants
+type PosterImage struct {
+	Key        string       `bson:"key" json:"-"`
+	Width      int          `bson:"width" json:"width" example:"2000"`
+	Height     int          `bson:"height" json:"height" example:"3000"`
+	Sizes      []PosterSize `bson:"sizes" json:"sizes"`
+	UploadedAt time.Time    `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// PosterSize is one resized variant of an uploaded poster
+type PosterSize struct {
+	Width  int    `bson:"width" json:"width" example:"342"`
+	Height int    `bson:"height" json:"height" example:"513"`
+	Format string `bson:"format" json:"format" example:"webp"`
+	URL    string `bson:"url" json:"url" example:"http://localhost:8080/media/posters/507f1f77bcf86cd799439011/3f2a9c1e/w342.webp"`
+}
+
+// BlobKeys returns the keys of the resized variants, which are stored as
+// Key/w<width>.<jpg|webp>
+func (p *PosterImage) BlobKeys() []string {
+	keys := make([]string, 0, len(p.Sizes))
+	for _, size := range p.Sizes {
+		extension := "jpg"
+		if size.Format == "webp" {
+			extension = "webp"
+		}
+		keys = append(keys, p.Key+"/w"+strconv.Itoa(size.Width)+"."+extension)
+	}
+	return keys
+}
+
+// VideoAsset is a video file of a movie, streamed from GET /stream/:movie_id/:asset
+type VideoAsset struct {
+	Key         string    `bson:"key" json:"-"`
+	ContentType string    `bson:"content_type" json:"content_type" example:"video/mp4"`
+	Size        int64     `bson:"size" json:"size" example:"1073741824"`
+	Width       int       `bson:"width,omitempty" json:"width,omitempty" example:"1920"`
+	Height      int       `bson:"height,omitempty" json:"height,omitempty" example:"1080"` // checked against the plan's quality cap
+	UploadedAt  time.Time `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// BlobKeys returns the keys of the files the movie points at: poster variants,
+// video assets and subtitle tracks. HLS segments are listed by their playlists.
+func (m *Movie) BlobKeys() []string {
+	var keys []string
+	if m.Poster != nil {
+		keys = append(keys, m.Poster.BlobKeys()...)
+	}
+	for _, asset := range m.Videos {
+		keys = append(keys, asset.Key)
+	}
+	for _, track := range m.Subtitles {
+		keys = append(keys, track.Key)
+	}
+	return keys
 }
 
 // MovieCreateRequest for creating a new movie (without ID)
 type MovieCreateRequest struct {
-	ImdbID      string   `json:"imdb_id" binding:"required,min=9,max=10" example:"tt0111161"`
-	Title       string   `json:"title" binding:"required,min=2,max=500" example:"The Shawshank Redemption"`
-	PosterPath  string   `json:"poster_path" binding:"required,url" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
-	YouTubeID   string   `json:"youtube_id" binding:"required,min=11,max=11" example:"6hB3S9bIaco"`
-	Genre       []Genre  `json:"genre" binding:"required,min=1,dive"`
-	AdminReview string   `json:"admin_review" binding:"omitempty,max=1000" example:"One of the greatest movies of all time"`
-	Ranking     Ranking  `json:"ranking" binding:"required"`
-}
-
-// MovieUpdateRequest for updating an existing movie
-type MovieUpdateRequest struct {
-	ImdbID      string   `json:"imdb_id" binding:"omitempty,min=9,max=10" example:"tt0111161"`
-	Title       string   `json:"title" binding:"omitempty,min=2,max=500" example:"The Shawshank Redemption"`
-	PosterPath  string   `json:"poster_path" binding:"omitempty,url" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
-	YouTubeID   string   `json:"youtube_id" binding:"omitempty,min=11,max=11" example:"6hB3S9bIaco"`
-	Genre       []Genre  `json:"genre" binding:"omitempty,min=1,dive"`
-	AdminReview string   `json:"admin_review" binding:"omitempty,max=1000" example:"Updated review"`
-	Ranking     Ranking  `json:"ranking" binding:"omitempty"`
+	ImdbID            string                      `json:"imdb_id" binding:"required,min=9,max=10" example:"tt0111161"`
+	Title             string                      `json:"title" binding:"required,min=2,max=500" example:"The Shawshank Redemption"`
+	PosterPath        string                      `json:"poster_path" binding:"omitempty,url" example:"https://image.tmdb.org/t/p/w500/q6y0Go1tsGEsmtFryDOJo3dEmqu.jpg"`
+	YouTubeID         string                      `json:"youtube_id" binding:"required,min=11,max=11" example:"6hB3S9bIaco"`
+	Genre             []Genre                     `json:"genre" binding:"required,min=1,dive"`
+	AdminReview       string                      `json:"admin_review" binding:"omitempty,max=1000" example:"One of the greatest movies of all time"`
+	Ranking           Ranking                     `json:"ranking" binding:"required"`
+	ReleaseDate       string                      `json:"release_date" binding:"omitempty,datetime=2006-01-02" example:"1994-09-23"`
+	Runtime           int                         `json:"runtime" binding:"omitempty,min=1,max=1440" example:"142"`
+	Synopsis          string                      `json:"synopsis" binding:"omitempty,max=5000" example:"Two imprisoned men bond over a number of years."`
+	OriginalLanguage  string                      `json:"original_language" binding:"omitempty,bcp47_language_tag" example:"en"`
+	SpokenLanguages   []string                    `json:"spoken_languages" binding:"omitempty,dive,bcp47_language_tag" example:"en"`
+	SubtitleLanguages []string                    `json:"subtitle_languages" binding:"omitempty,dive,bcp47_language_tag" example:"id"`
+	Country           string                      `json:"country" binding:"omitempty,iso3166_1_alpha2" example:"US"`
+	AgeCertification  string                      `json:"age_certification" binding:"omitempty,oneof=G PG PG-13 R NC-17 NR" example:"R"`
+	MinPlan           string                      `json:"min_plan" binding:"omitempty,oneof=free basic premium" example:"basic"`
+	Credits           []Credit                    `json:"credits" binding:"omitempty,dive"`
+	Translations      map[string]MovieTranslation `json:"translations,omitempty" binding:"omitempty,dive,keys,bcp47_language_tag,endkeys"`
 }
 
+// MovieUpdateRequest replaces every editable field of an existing movie. It is the
+// body of PUT and the document PATCH requests are applied to, so it carries the
+// same rules as MovieCreateRequest.
+type MovieUpdateRequest MovieCreateRequest
+
 // MovieFilterParams for query parameters
 type MovieFilterParams struct {
 	Genre   string `form:"genre" example:"Action"`
@@ -58,9 +165,25 @@ type MovieFilterParams struct {
 	Skip    int    `form:"skip" example:"0"`
 }
 
+// MovieMetadataFilter holds the optional metadata filters for movie listings
+type MovieMetadataFilter struct {
+	YearFrom      int     `form:"year_from" binding:"omitempty,min=1870,max=2200" example:"1990"`
+	YearTo        int     `form:"year_to" binding:"omitempty,min=1870,max=2200" example:"1999"`
+	MinRuntime    int     `form:"min_runtime" binding:"omitempty,min=1" example:"90"`
+	MaxRuntime    int     `form:"max_runtime" binding:"omitempty,min=1" example:"180"`
+	Language      string  `form:"language" binding:"omitempty,bcp47_language_tag" example:"en"`
+	Subtitle      string  `form:"subtitle" binding:"omitempty,bcp47_language_tag" example:"id"`
+	Country       string  `form:"country" binding:"omitempty,iso3166_1_alpha2" example:"US"`
+	Certification string  `form:"certification" binding:"omitempty,oneof=G PG PG-13 R NC-17 NR" example:"PG-13"`
+	Director      string  `form:"director" example:"Nolan"`
+	Cast          string  `form:"cast" example:"Morgan Freeman"`
+	MinRating     float64 `form:"min_rating" binding:"omitempty,min=1,max=10" example:"7.5"`
+	MinRatings    int64   `form:"min_ratings" binding:"omitempty,min=1" example:"50"`
+}
+
 // ToMovie converts MovieCreateRequest to Movie
 func (req *MovieCreateRequest) ToMovie() Movie {
-	return Movie{
+	movie := Movie{
 		ID:          bson.NewObjectID(),
 		ImdbID:      req.ImdbID,
 		Title:       req.Title,
@@ -69,34 +192,95 @@ func (req *MovieCreateRequest) ToMovie() Movie {
 		Genre:       req.Genre,
 		AdminReview: req.AdminReview,
 		Ranking:     req.Ranking,
+
+		ReleaseDate:       req.ReleaseDate,
+		Runtime:           req.Runtime,
+		Synopsis:          req.Synopsis,
+		OriginalLanguage:  req.OriginalLanguage,
+		SpokenLanguages:   nonNilStrings(req.SpokenLanguages),
+		SubtitleLanguages: nonNilStrings(req.SubtitleLanguages),
+		Country:           req.Country,
+		AgeCertification:  defaultString(req.AgeCertification, CertificationNR),
+		MinPlan:           defaultString(req.MinPlan, PlanFree),
+		Credits:           nonNilCredits(req.Credits),
+		Translations:      req.Translations,
+		UserRating:        NewRatingSummary(),
 	}
+	movie.Search = NewMovieSearchIndex(&movie)
+	return movie
 }
 
-// ToMap converts MovieUpdateRequest to map for MongoDB update
-func (req *MovieUpdateRequest) ToMap() map[string]interface{} {
-	update := make(map[string]interface{})
-	
-	if req.ImdbID != "" {
-		update["imdb_id"] = req.ImdbID
-	}
-	if req.Title != "" {
-		update["title"] = req.Title
-	}
-	if req.PosterPath != "" {
-		update["poster_path"] = req.PosterPath
-	}
-	if req.YouTubeID != "" {
-		update["youtube_id"] = req.YouTubeID
+// NewMovieUpdateRequest returns the editable fields of a movie
+func NewMovieUpdateRequest(movie *Movie) MovieUpdateRequest {
+	return MovieUpdateRequest{
+		ImdbID:            movie.ImdbID,
+		Title:             movie.Title,
+		PosterPath:        movie.PosterPath,
+		YouTubeID:         movie.YouTubeID,
+		Genre:             movie.Genre,
+		AdminReview:       movie.AdminReview,
+		Ranking:           movie.Ranking,
+		ReleaseDate:       movie.ReleaseDate,
+		Runtime:           movie.Runtime,
+		Synopsis:          movie.Synopsis,
+		OriginalLanguage:  movie.OriginalLanguage,
+		SpokenLanguages:   movie.SpokenLanguages,
+		SubtitleLanguages: movie.SubtitleLanguages,
+		Country:           movie.Country,
+		AgeCertification:  movie.AgeCertification,
+		MinPlan:           movie.MinPlan,
+		Credits:           movie.Credits,
+		Translations:      movie.Translations,
 	}
-	if len(req.Genre) > 0 {
-		update["genre"] = req.Genre
+}
+
+// ToMovie converts MovieUpdateRequest to the replacement Movie for the given ID
+func (req *MovieUpdateRequest) ToMovie(id bson.ObjectID) Movie {
+	movie := (*MovieCreateRequest)(req).ToMovie()
+	movie.ID = id
+	return movie
+}
+
+// nonNilStrings makes sure list fields are stored as empty arrays instead of null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
 	}
-	if req.AdminReview != "" {
-		update["admin_review"] = req.AdminReview
+	return values
+}
+
+func nonNilCredits(credits []Credit) []Credit {
+	if credits == nil {
+		return []Credit{}
 	}
-	if req.Ranking.RankingValue > 0 {
-		update["ranking"] = req.Ranking
+	return credits
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
 	}
-	
-	return update
-}
\ No newline at end of file
+	return value
+}
+
+// CollectionState summarises a collection for HTTP cache validators. Revision
+// changes on every write, LastModified is the time of the latest write.
+type CollectionState struct {
+	Count        int64     `bson:"count" json:"count"`
+	Revision     int64     `bson:"revision" json:"revision"`
+	LastModified time.Time `bson:"last_modified" json:"last_modified"`
+}
+
+// FacetBucket represents a single facet value with its document count
+type FacetBucket struct {
+	Value int    `bson:"value" json:"value" example:"2"`
+	Name  string `bson:"name" json:"name" example:"Drama"`
+	Count int64  `bson:"count" json:"count" example:"42"`
+}
+
+// MovieFacets holds per-genre and per-ranking counts for the browse filters
+type MovieFacets struct {
+	Genres   []FacetBucket `bson:"genres" json:"genres"`
+	Rankings []FacetBucket `bson:"rankings" json:"rankings"`
+	Total    int64         `bson:"total" json:"total" example:"128"`
+}
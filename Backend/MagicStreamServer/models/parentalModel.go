@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// ParentalControls hold the account's parental PIN. Once it is set, the PIN
+// guards the maturity limits of the profiles and the locked profiles.
+type ParentalControls struct {
+	PINHash        string     `bson:"pin_hash"`
+	FailedAttempts int        `bson:"failed_attempts"`
+	LockedUntil    *time.Time `bson:"locked_until,omitempty"` // set after too many wrong PINs
+	UpdatedAt      time.Time  `bson:"updated_at"`
+}
+
+// Locked reports whether PIN checks are refused at the given time
+func (p *ParentalControls) Locked(now time.Time) bool {
+	return p.LockedUntil != nil && now.Before(*p.LockedUntil)
+}
+
+// ParentalControlsRequest sets the PIN on first use. Afterwards pin must be the
+// current PIN, and new_pin changes it.
+type ParentalControlsRequest struct {
+	PIN    string `json:"pin" binding:"required,numeric,min=4,max=8" example:"1234"`
+	NewPIN string `json:"new_pin" binding:"omitempty,numeric,min=4,max=8" example:"5678"`
+}
+
+// ParentalPINRequest confirms an action with the parental PIN
+type ParentalPINRequest struct {
+	PIN string `json:"pin" binding:"required,numeric,min=4,max=8" example:"1234"`
+}
+
+// ParentalControlsResponse is the safe view of the parental controls
+type ParentalControlsResponse struct {
+	PINSet      bool       `json:"pin_set" example:"true"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+// NewParentalControlsResponse hides the PIN hash and attempt counter
+func NewParentalControlsResponse(p *ParentalControls) ParentalControlsResponse {
+	if p == nil {
+		return ParentalControlsResponse{}
+	}
+	response := ParentalControlsResponse{PINSet: true}
+	if p.Locked(time.Now()) {
+		response.LockedUntil = p.LockedUntil
+	}
+	return response
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Credit jobs used for cast and crew filtering
+const (
+	JobActor    = "Actor"
+	JobDirector = "Director"
+)
+
+// Person represents a cast or crew member in the people collection
+type Person struct {
+	ID                 bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty" example:"507f1f77bcf86cd799439011"`
+	Name               string        `bson:"name" json:"name" example:"Morgan Freeman"`
+	Biography          string        `bson:"biography" json:"biography" example:"American actor and narrator."`
+	PhotoURL           string        `bson:"photo_url" json:"photo_url" example:"https://image.tmdb.org/t/p/w300/jPsLqiYGSofU4s6BjrxnefMfabb.jpg"`
+	KnownForDepartment string        `bson:"known_for_department" json:"known_for_department" example:"Acting"`
+	CreatedAt          time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// PersonRequest for creating or replacing a person
+type PersonRequest struct {
+	Name               string `json:"name" binding:"required,min=1,max=200" example:"Morgan Freeman"`
+	Biography          string `json:"biography" binding:"omitempty,max=10000" example:"American actor and narrator."`
+	PhotoURL           string `json:"photo_url" binding:"omitempty,url" example:"https://image.tmdb.org/t/p/w300/jPsLqiYGSofU4s6BjrxnefMfabb.jpg"`
+	KnownForDepartment string `json:"known_for_department" binding:"omitempty,oneof=Acting Directing Writing Production Camera Editing Sound Art Crew" example:"Acting"`
+}
+
+// PersonMergeRequest lists duplicate people to fold into the target person
+type PersonMergeRequest struct {
+	SourceIDs []string `json:"source_ids" binding:"required,min=1,unique,dive,len=24,hexadecimal" example:"507f1f77bcf86cd799439012"`
+}
+
+// Credit links a movie to a person, with the person's name denormalized for listings
+type Credit struct {
+	PersonID  bson.ObjectID `bson:"person_id" json:"person_id" binding:"required" example:"507f1f77bcf86cd799439011"`
+	Name      string        `bson:"name" json:"name" example:"Morgan Freeman"`
+	Job       string        `bson:"job" json:"job" binding:"required,min=1,max=100" example:"Actor"`
+	Character string        `bson:"character" json:"character" binding:"omitempty,max=200" example:"Ellis Boyd 'Red' Redding"`
+	Order     int           `bson:"order" json:"order" binding:"omitempty,min=0" example:"1"`
+}
+
+// CreditDetail is a credit with the full person record attached
+type CreditDetail struct {
+	Credit `bson:",inline"`
+	Person *Person `json:"person,omitempty"`
+}
+
+// ToPerson converts PersonRequest to a new Person
+func (req *PersonRequest) ToPerson() Person {
+	now := time.Now()
+	return Person{
+		ID:                 bson.NewObjectID(),
+		Name:               req.Name,
+		Biography:          req.Biography,
+		PhotoURL:           req.PhotoURL,
+		KnownForDepartment: req.KnownForDepartment,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
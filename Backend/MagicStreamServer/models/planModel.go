@@ -0,0 +1,51 @@
+package models
+
+// Subscription plans, from the cheapest up
+const (
+	PlanFree    = "free"
+	PlanBasic   = "basic"
+	PlanPremium = "premium"
+)
+
+// planOrder ranks the plans; a plan includes every movie of the plans before it
+var planOrder = []string{PlanFree, PlanBasic, PlanPremium}
+
+var planNames = map[string]string{
+	PlanFree:    "Free",
+	PlanBasic:   "Basic",
+	PlanPremium: "Premium",
+}
+
+// Plan describes a subscription plan and the features it comes with
+type Plan struct {
+	ID         string `json:"id" example:"basic"`
+	Name       string `json:"name" example:"Basic"`
+	MaxStreams int    `json:"max_streams" example:"2"`    // 0 means unlimited
+	MaxQuality int    `json:"max_quality" example:"1080"` // highest rendition height in pixels, 0 means unlimited
+}
+
+// PlanIDs returns every plan from the cheapest up
+func PlanIDs() []string {
+	return append([]string(nil), planOrder...)
+}
+
+// PlanName returns the display name of a plan
+func PlanName(plan string) string {
+	return planNames[plan]
+}
+
+// PlanIncludes reports whether a subscriber of plan may watch movies that
+// require the given plan. Movies without a minimum plan are free, and
+// unknown plans count as free.
+func PlanIncludes(plan, required string) bool {
+	return planRank(plan) >= planRank(required)
+}
+
+func planRank(plan string) int {
+	for i, id := range planOrder {
+		if id == plan {
+			return i
+		}
+	}
+	return 0
+}
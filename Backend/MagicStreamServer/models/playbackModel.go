@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PlaybackSession holds the signed URLs a player uses to stream a movie. The
+// URLs carry their own authorization, so no Authorization header is needed.
+type PlaybackSession struct {
+	SessionID         string            `json:"session_id" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+	MovieID           string            `json:"movie_id" example:"664f1a2b3c4d5e6f7a8b9c0d"`
+	ExpiresAt         time.Time         `json:"expires_at"`
+	HeartbeatInterval int               `json:"heartbeat_interval_seconds" example:"30"`
+	HLSURL            string            `json:"hls_url,omitempty" example:"http://localhost:5000/api/v1/play/eyJr.../hls/664f1a2b3c4d5e6f7a8b9c0d/master.m3u8"`
+	Videos            map[string]string `json:"videos,omitempty"`
+}
+
+// PlaybackStartRequest is the optional body of a playback start
+type PlaybackStartRequest struct {
+	Device string `json:"device" binding:"omitempty,max=100" example:"living-room-tv"`
+	// ReplaceSessionID stops one of the caller's active streams to make room for this one
+	ReplaceSessionID string `json:"replace_session_id" binding:"omitempty,len=24,hexadecimal" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+}
+
+// StreamSession is a stream playing on one of a user's devices. Players keep
+// it alive with heartbeats; once they stop it expires and frees its slot.
+type StreamSession struct {
+	ID              bson.ObjectID `bson:"_id" json:"session_id" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+	UserID          string        `bson:"user_id" json:"-"`
+	MovieID         bson.ObjectID `bson:"movie_id" json:"movie_id" example:"664f1a2b3c4d5e6f7a8b9c0d"`
+	MovieTitle      string        `bson:"movie_title" json:"movie_title" example:"The Shawshank Redemption"`
+	Device          string        `bson:"device" json:"device" example:"living-room-tv"`
+	UserAgent       string        `bson:"user_agent" json:"user_agent" example:"Mozilla/5.0 (SMART-TV; Linux; Tizen 6.0)"`
+	StartedAt       time.Time     `bson:"started_at" json:"started_at"`
+	LastHeartbeatAt time.Time     `bson:"last_heartbeat_at" json:"last_heartbeat_at"`
+	ExpiresAt       time.Time     `bson:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Profile is one viewer of an account. Favourite genres, the maturity limit,
+// the watchlist and the watch history belong to a profile; the login, the
+// parental PIN and the stream limit to the account.
+type Profile struct {
+	ID               bson.ObjectID `bson:"_id" json:"profile_id" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+	Name             string        `bson:"name" json:"name" example:"Kids"`
+	Avatar           string        `bson:"avatar,omitempty" json:"avatar,omitempty" example:"https://cdn.magicstream.com/avatars/robot.png"`
+	FavouriteGenres  []Genre       `bson:"favourite_genres" json:"favourite_genres"`
+	MaxCertification string        `bson:"max_certification,omitempty" json:"max_certification,omitempty" example:"PG"` // empty while no limit is set
+	Locked           bool          `bson:"locked" json:"locked" example:"false"`                                        // selecting it takes the parental PIN
+	CreatedAt        time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// ProfileRequest creates or replaces a profile. Once a parental PIN is set,
+// creating a profile and changing max_certification or locked take the PIN.
+type ProfileRequest struct {
+	Name             string  `json:"name" binding:"required,min=1,max=50" example:"Kids"`
+	Avatar           string  `json:"avatar" binding:"omitempty,url" example:"https://cdn.magicstream.com/avatars/robot.png"`
+	FavouriteGenres  []Genre `json:"favourite_genres" binding:"omitempty,dive"`
+	MaxCertification string  `json:"max_certification" binding:"omitempty,oneof=G PG PG-13 R NC-17" example:"PG"`
+	Locked           bool    `json:"locked" example:"false"`
+	PIN              string  `json:"pin" binding:"omitempty,numeric,min=4,max=8" example:"1234"`
+}
+
+// ProfileSelectRequest makes a profile the active one. The PIN is only needed
+// for locked profiles.
+type ProfileSelectRequest struct {
+	PIN string `json:"pin" binding:"omitempty,numeric,min=4,max=8" example:"1234"`
+}
+
+// Profile returns the profile with the given ID, or the default profile for a
+// zero ID. It returns nil when there is no such profile.
+func (u *User) Profile(id bson.ObjectID) *Profile {
+	if id.IsZero() {
+		if len(u.Profiles) == 0 {
+			return nil
+		}
+		return &u.Profiles[0]
+	}
+	for i := range u.Profiles {
+		if u.Profiles[i].ID == id {
+			return &u.Profiles[i]
+		}
+	}
+	return nil
+}
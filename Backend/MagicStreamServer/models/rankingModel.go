@@ -0,0 +1,32 @@
+package models
+
+// Ranking is a level of the rankings catalog. Movies embed the ranking they are
+// given; requests only need ranking_value, the name and order are resolved from
+// the rankings collection on the server.
+type Ranking struct {
+	RankingValue int    `bson:"ranking_value" json:"ranking_value" binding:"required,min=1" example:"1"`
+	RankingName  string `bson:"ranking_name" json:"ranking_name" example:"Excellent"`
+	Order        int    `bson:"order" json:"order" example:"1"` // lower is better
+}
+
+// RankingRequest for creating a ranking level
+type RankingRequest struct {
+	RankingValue int    `json:"ranking_value" binding:"required,min=1" example:"6"`
+	RankingName  string `json:"ranking_name" binding:"required,min=2,max=50" example:"Masterpiece"`
+	Order        int    `json:"order" binding:"required,min=1" example:"1"`
+}
+
+// RankingUpdateRequest for renaming or reordering a ranking level
+type RankingUpdateRequest struct {
+	RankingName string `json:"ranking_name" binding:"required,min=2,max=50" example:"Masterpiece"`
+	Order       int    `json:"order" binding:"required,min=1" example:"1"`
+}
+
+// ToRanking converts RankingRequest to Ranking
+func (req *RankingRequest) ToRanking() Ranking {
+	return Ranking{
+		RankingValue: req.RankingValue,
+		RankingName:  req.RankingName,
+		Order:        req.Order,
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Review sort orders accepted by the review listing
+const (
+	ReviewSortHelpful = "helpful"
+	ReviewSortRecent  = "recent"
+)
+
+// Review is a user's star rating of a movie with an optional written review
+type Review struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty" example:"507f1f77bcf86cd799439011"`
+	MovieID      bson.ObjectID `bson:"movie_id" json:"movie_id" example:"507f1f77bcf86cd799439012"`
+	UserID       string        `bson:"user_id" json:"user_id" example:"68385b9981097c6b4042dab4"`
+	UserName     string        `bson:"user_name" json:"user_name" example:"John D."`
+	Rating       int           `bson:"rating" json:"rating" example:"9"`
+	Title        string        `bson:"title" json:"title" example:"Hope is a good thing"`
+	Body         string        `bson:"body" json:"body" example:"Still holds up after all these years."`
+	HelpfulCount int           `bson:"helpful_count" json:"helpful_count" example:"12"`
+	HelpfulBy    []string      `bson:"helpful_by" json:"-"`
+	CreatedAt    time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// ReviewRequest for creating or replacing the caller's review of a movie
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=10" example:"9"`
+	Title  string `json:"title" binding:"omitempty,max=200" example:"Hope is a good thing"`
+	Body   string `json:"body" binding:"omitempty,max=5000" example:"Still holds up after all these years."`
+}
+
+// RatingSummary is the denormalized aggregate of a movie's user ratings.
+// Histogram is keyed by star value "1".."10".
+type RatingSummary struct {
+	Average   float64          `bson:"average" json:"average" example:"8.6"`
+	Count     int64            `bson:"count" json:"count" example:"1520"`
+	Sum       int64            `bson:"sum" json:"-"`
+	Histogram map[string]int64 `bson:"histogram" json:"histogram"`
+}
+
+// NewRatingSummary returns an empty summary with every histogram bucket present
+func NewRatingSummary() RatingSummary {
+	histogram := make(map[string]int64, 10)
+	for stars := 1; stars <= 10; stars++ {
+		histogram[strconv.Itoa(stars)] = 0
+	}
+	return RatingSummary{Histogram: histogram}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Revision actions recorded in the movie history
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRestore = "restore"
+	RevisionActionImport  = "import"
+	RevisionActionRevert  = "revert"
+)
+
+// FieldChange describes how a single top-level movie field changed
+type FieldChange struct {
+	Field string      `bson:"field" json:"field" example:"title"`
+	Old   interface{} `bson:"old" json:"old"`
+	New   interface{} `bson:"new" json:"new"`
+}
+
+// MovieRevision is one entry in a movie's edit history
+type MovieRevision struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"_id,omitempty" example:"507f1f77bcf86cd799439011"`
+	MovieID      bson.ObjectID `bson:"movie_id" json:"movie_id" example:"507f1f77bcf86cd799439012"`
+	Revision     int           `bson:"revision" json:"revision" example:"3"`
+	Action       string        `bson:"action" json:"action" example:"update"`
+	Actor        string        `bson:"actor" json:"actor" example:"68385b9981097c6b4042dab4"`
+	CreatedAt    time.Time     `bson:"created_at" json:"created_at"`
+	Changes      []FieldChange `bson:"changes" json:"changes"`
+	RevertedFrom int           `bson:"reverted_from,omitempty" json:"reverted_from,omitempty" example:"1"`
+	Snapshot     Movie         `bson:"snapshot" json:"snapshot"`
+}
+
+// RevisionDiff compares the movie state at two revisions
+type RevisionDiff struct {
+	MovieID bson.ObjectID `json:"movie_id" example:"507f1f77bcf86cd799439012"`
+	From    int           `json:"from" example:"1"`
+	To      int           `json:"to" example:"3"`
+	Changes []FieldChange `json:"changes"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// Subscription states
+const (
+	SubscriptionTrial    = "trial"
+	SubscriptionActive   = "active"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+)
+
+// subscriptionTransitions lists the states each state may move to. An account
+// without a subscription may start a trial or pay right away; a trial is only
+// offered once.
+var subscriptionTransitions = map[string][]string{
+	"":                   {SubscriptionTrial, SubscriptionActive},
+	SubscriptionTrial:    {SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled},
+	SubscriptionActive:   {SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled},
+	SubscriptionPastDue:  {SubscriptionActive, SubscriptionPastDue, SubscriptionCanceled},
+	SubscriptionCanceled: {SubscriptionActive},
+}
+
+// CanTransition reports whether a subscription may move from one state to
+// another. The empty state stands for no subscription.
+func CanTransition(from, to string) bool {
+	for _, state := range subscriptionTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is the account's paid plan as last reported by the payment
+// provider. Access to the plan ends at AccessUntil whatever the state, so a
+// missed webhook never grants access for longer than was paid for.
+type Subscription struct {
+	Plan                   string     `bson:"plan" json:"plan" example:"basic"`
+	Status                 string     `bson:"status" json:"status" example:"active"`
+	ProviderCustomerID     string     `bson:"provider_customer_id,omitempty" json:"-"`
+	ProviderSubscriptionID string     `bson:"provider_subscription_id,omitempty" json:"-"`
+	TrialEndsAt            *time.Time `bson:"trial_ends_at,omitempty" json:"trial_ends_at,omitempty"`
+	CurrentPeriodEnd       *time.Time `bson:"current_period_end,omitempty" json:"current_period_end,omitempty"`
+	AccessUntil            time.Time  `bson:"access_until" json:"access_until"`
+	LastEventAt            time.Time  `bson:"last_event_at" json:"-"` // creation time of the last applied provider event
+	UpdatedAt              time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// EffectivePlan returns the plan the user may watch at the given time. Users
+// without a subscription, or whose access ran out, are on the free plan.
+func (u *User) EffectivePlan(now time.Time) string {
+	if u.Subscription == nil || !now.Before(u.Subscription.AccessUntil) {
+		return PlanFree
+	}
+	return u.Subscription.Plan
+}
+
+// PaymentEvent is a webhook notification from the payment provider
+type PaymentEvent struct {
+	ID      string           `json:"id" binding:"required,max=255" example:"evt_1PqX2a"`
+	Type    string           `json:"type" binding:"required,oneof=subscription.trial_started invoice.paid invoice.payment_failed subscription.updated subscription.canceled" example:"invoice.paid"`
+	Created int64            `json:"created" binding:"required,min=1" example:"1717430400"`
+	Data    PaymentEventData `json:"data"`
+}
+
+// PaymentEventData is the subscription an event is about. The user ID is
+// passed to the provider at checkout and sent back on every event.
+type PaymentEventData struct {
+	UserID           string     `json:"user_id" binding:"required" example:"68385b9981097c6b4042dab4"`
+	CustomerID       string     `json:"customer_id" binding:"omitempty,max=255" example:"cus_Q8x1"`
+	SubscriptionID   string     `json:"subscription_id" binding:"omitempty,max=255" example:"sub_Q8x1"`
+	Plan             string     `json:"plan" binding:"omitempty,oneof=basic premium" example:"basic"`
+	TrialEndsAt      *time.Time `json:"trial_ends_at"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end"`
+}
+
+// paymentEventStatus is the state each event type moves the subscription to;
+// subscription.updated keeps the state, whatever it is, and changes the plan
+var paymentEventStatus = map[string]string{
+	"subscription.trial_started": SubscriptionTrial,
+	"invoice.paid":               SubscriptionActive,
+	"invoice.payment_failed":     SubscriptionPastDue,
+	"subscription.canceled":      SubscriptionCanceled,
+}
+
+// PaymentEventStatus returns the state an event moves a subscription in the
+// given state to
+func PaymentEventStatus(eventType, current string) string {
+	if status, ok := paymentEventStatus[eventType]; ok {
+		return status
+	}
+	return current
+}
+
+// ProcessedPaymentEvent records a webhook event that was applied, so
+// redelivered events are acknowledged without applying them twice
+type ProcessedPaymentEvent struct {
+	ID          string    `bson:"_id"`
+	Type        string    `bson:"type"`
+	UserID      string    `bson:"user_id"`
+	Outcome     string    `bson:"outcome"` // applied or ignored
+	ProcessedAt time.Time `bson:"processed_at"`
+}
+
+// SubscriptionResponse is the caller's subscription with the plan they may watch
+type SubscriptionResponse struct {
+	Subscription *Subscription `json:"subscription"` // null without a subscription
+	Plan         Plan          `json:"plan"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Subtitle track kinds, as in the kind attribute of the HTML track element
+const (
+	SubtitleKindSubtitles = "subtitles"
+	SubtitleKindCaptions  = "captions"
+)
+
+// SubtitleTrack is a WebVTT subtitle or caption file of a movie. A movie has at
+// most one track per language and kind.
+type SubtitleTrack struct {
+	Lang       string    `bson:"lang" json:"lang" example:"id"`
+	Label      string    `bson:"label" json:"label" example:"Indonesia"`
+	Kind       string    `bson:"kind" json:"kind" example:"subtitles"`
+	URL        string    `bson:"url" json:"url" example:"http://localhost:5000/media/subtitles/664f1a2b3c4d5e6f7a8b9c0d/id/subtitles-3f2a9c1e5b7d8a60.vtt"`
+	Key        string    `bson:"key" json:"-"`
+	UploadedAt time.Time `bson:"uploaded_at" json:"uploaded_at"`
+}
+
+// SubtitleUploadRequest holds the form fields sent with a subtitle file
+type SubtitleUploadRequest struct {
+	Label string `form:"label" binding:"omitempty,max=100" example:"Indonesia"`
+	Kind  string `form:"kind" binding:"omitempty,oneof=subtitles captions" example:"subtitles"`
+}
@@ -14,6 +14,7 @@ type TokenPair struct {
 type RefreshToken struct {
 	ID        bson.ObjectID `bson:"_id,omitempty"`
 	UserID    string    `bson:"user_id"`
+	ProfileID bson.ObjectID `bson:"profile_id,omitempty"`
 	Token     string    `bson:"token"`
 	ExpiresAt time.Time `bson:"expires_at"`
 	CreatedAt time.Time `bson:"created_at"`
@@ -8,19 +8,22 @@ import (
 
 // User is the MongoDB document model
 type User struct {
-	ID              bson.ObjectID `bson:"_id,omitempty"`
-	UserID          string        `bson:"user_id"`
-	FirstName       string        `bson:"first_name"`
-	LastName        string        `bson:"last_name"`
-	Email           string        `bson:"email"`
-	Password        string        `bson:"password"` // hashed
-	Role            string        `bson:"role"`
-	CreatedAt       time.Time     `bson:"created_at"`
-	UpdatedAt       time.Time     `bson:"updated_at"`
-	FavouriteGenres []Genre       `bson:"favourite_genres"`
+	ID               bson.ObjectID     `bson:"_id,omitempty"`
+	UserID           string            `bson:"user_id"`
+	FirstName        string            `bson:"first_name"`
+	LastName         string            `bson:"last_name"`
+	Email            string            `bson:"email"`
+	Password         string            `bson:"password"` // hashed
+	Role             string            `bson:"role"`
+	CreatedAt        time.Time         `bson:"created_at"`
+	UpdatedAt        time.Time         `bson:"updated_at"`
+	Profiles         []Profile         `bson:"profiles"`                                             // the first one is the default
+	ParentalControls *ParentalControls `bson:"parental_controls,omitempty" json:"-"`                 // see /me/parental-controls
+	Subscription     *Subscription     `bson:"subscription,omitempty" json:"subscription,omitempty"` // set by the payment webhook
 }
 
-// UserRegister is used for incoming registration requests
+// UserRegister is used for incoming registration requests. The favourite
+// genres go to the default profile, named after the user.
 type UserRegister struct {
 	FirstName       string  `json:"first_name" binding:"required,min=2,max=100" example:"John"`
 	LastName        string  `json:"last_name" binding:"required,min=2,max=100" example:"Doe"`
@@ -29,20 +32,25 @@ type UserRegister struct {
 	FavouriteGenres []Genre `json:"favourite_genres" binding:"required,dive"`
 }
 
-// UserLogin is used for login requests
+// UserLogin is used for login requests. Without a profile ID the tokens are for
+// the default profile; a locked profile also takes the parental PIN.
 type UserLogin struct {
-	Email    string `json:"email" binding:"required,email" example:"john.doe@example.com"`
-	Password string `json:"password" binding:"required,min=6" example:"password123"`
+	Email     string `json:"email" binding:"required,email" example:"john.doe@example.com"`
+	Password  string `json:"password" binding:"required,min=6" example:"password123"`
+	ProfileID string `json:"profile_id" binding:"omitempty,mongodb" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+	PIN       string `json:"pin" binding:"omitempty,numeric,min=4,max=8" example:"1234"`
 }
 
 // UserResponse is the safe output model (no password, no internal IDs)
 type UserResponse struct {
-	UserID          string  `json:"user_id" example:"507f1f77bcf86cd799439011"`
-	FirstName       string  `json:"first_name" example:"John"`
-	LastName        string  `json:"last_name" example:"Doe"`
-	Email           string  `json:"email" example:"john.doe@example.com"`
-	Role            string  `json:"role" example:"USER"`
-	Token           string  `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	RefreshToken    string  `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	FavouriteGenres []Genre `json:"favourite_genres"`
-}
\ No newline at end of file
+	UserID          string    `json:"user_id" example:"507f1f77bcf86cd799439011"`
+	FirstName       string    `json:"first_name" example:"John"`
+	LastName        string    `json:"last_name" example:"Doe"`
+	Email           string    `json:"email" example:"john.doe@example.com"`
+	Role            string    `json:"role" example:"USER"`
+	Token           string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken    string    `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ProfileID       string    `json:"profile_id" example:"6650f1c2e4b0a1b2c3d4e5f6"` // the profile the tokens are for
+	FavouriteGenres []Genre   `json:"favourite_genres"`                              // of that profile
+	Profiles        []Profile `json:"profiles"`
+}
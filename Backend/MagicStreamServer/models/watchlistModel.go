@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchlistEntry is a movie a profile saved for later. Entries are listed by
+// position, which the user controls by reordering the watchlist.
+type WatchlistEntry struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID    string        `bson:"user_id" json:"-"`
+	ProfileID bson.ObjectID `bson:"profile_id" json:"-"`
+	MovieID   bson.ObjectID `bson:"movie_id" json:"movie_id" example:"507f1f77bcf86cd799439011"`
+	Position  int           `bson:"position" json:"position" example:"0"`
+	AddedAt   time.Time     `bson:"added_at" json:"added_at"`
+}
+
+// WatchlistItem is a watchlist entry with its movie, as returned to the user
+type WatchlistItem struct {
+	Position int       `json:"position" example:"0"`
+	AddedAt  time.Time `json:"added_at"`
+	Movie    Movie     `json:"movie"`
+}
+
+// WatchlistRequest for adding a movie to the watchlist or moving it. Without a
+// position a new movie goes to the end and a saved movie stays where it is.
+type WatchlistRequest struct {
+	Position *int `json:"position" binding:"omitempty,min=0" example:"0"`
+}
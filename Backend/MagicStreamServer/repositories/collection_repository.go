@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// CollectionRepository defines the interface for curated movie collection data operations
+type CollectionRepository interface {
+	Create(ctx context.Context, collection *models.MovieCollection) error
+	FindAll(ctx context.Context) ([]models.MovieCollection, error)
+	FindByID(ctx context.Context, id string) (*models.MovieCollection, error)
+	Replace(ctx context.Context, id bson.ObjectID, collection *models.MovieCollection) error
+	Delete(ctx context.Context, id string) error
+	RemoveMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error)
+}
+
+// collectionRepositoryImpl implements CollectionRepository
+type collectionRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewCollectionRepository creates a new collection repository
+func NewCollectionRepository(collection *mongo.Collection) CollectionRepository {
+	return &collectionRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *collectionRepositoryImpl) Create(ctx context.Context, collection *models.MovieCollection) error {
+	_, err := r.collection.InsertOne(ctx, collection)
+	return err
+}
+
+// FindAll returns every collection in home page order
+func (r *collectionRepositoryImpl) FindAll(ctx context.Context) ([]models.MovieCollection, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	collections := []models.MovieCollection{}
+	if err := cursor.All(ctx, &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (r *collectionRepositoryImpl) FindByID(ctx context.Context, id string) (*models.MovieCollection, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrCollectionNotFound
+	}
+
+	var collection models.MovieCollection
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&collection)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+// Replace overwrites a collection, keeping its creation time
+func (r *collectionRepositoryImpl) Replace(ctx context.Context, id bson.ObjectID, collection *models.MovieCollection) error {
+	update := bson.M{"$set": bson.M{
+		"name":        collection.Name,
+		"description": collection.Description,
+		"kind":        collection.Kind,
+		"movie_ids":   collection.MovieIDs,
+		"query":       collection.Query,
+		"position":    collection.Position,
+		"updated_at":  time.Now(),
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (r *collectionRepositoryImpl) Delete(ctx context.Context, id string) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrCollectionNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// RemoveMovies takes the given movies out of every manual collection
+func (r *collectionRepositoryImpl) RemoveMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error) {
+	if len(movieIDs) == 0 {
+		return 0, nil
+	}
+
+	filter := bson.M{"movie_ids": bson.M{"$in": movieIDs}}
+	update := bson.M{"$pull": bson.M{"movie_ids": bson.M{"$in": movieIDs}}}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 var (
@@ -21,7 +22,9 @@ type GenreRepository interface {
 	FindByIDs(ctx context.Context, genreIDs []int) ([]models.Genre, error)
 	ValidateGenres(ctx context.Context, genres []models.Genre) (bool, error)
 	SeedGenres(ctx context.Context, genres []models.Genre) error
+	SetTranslations(ctx context.Context, genreID int, translations map[string]string) (*models.Genre, error)
 	Count(ctx context.Context) (int64, error)
+	State(ctx context.Context) (*models.CollectionState, error)
 }
 
 // genreRepositoryImpl implements GenreRepository
@@ -123,6 +126,60 @@ func (r *genreRepositoryImpl) SeedGenres(ctx context.Context, genres []models.Ge
 	return err
 }
 
+// SetTranslations replaces the translated names of a genre. The version and
+// updated_at stamps feed State, since genres are no longer insert-only.
+func (r *genreRepositoryImpl) SetTranslations(ctx context.Context, genreID int, translations map[string]string) (*models.Genre, error) {
+	update := bson.M{
+		"$inc":         bson.M{"version": 1},
+		"$currentDate": bson.M{"updated_at": true},
+	}
+	if len(translations) == 0 {
+		update["$unset"] = bson.M{"translations": ""}
+	} else {
+		update["$set"] = bson.M{"translations": translations}
+	}
+
+	var genre models.Genre
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"genre_id": genreID}, update, opts).Decode(&genre)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrGenreNotFound
+		}
+		return nil, err
+	}
+
+	return &genre, nil
+}
+
 func (r *genreRepositoryImpl) Count(ctx context.Context) (int64, error) {
 	return r.collection.CountDocuments(ctx, bson.M{})
-}
\ No newline at end of file
+}
+
+// State sums up the genre collection for cache validators. Inserted genres are
+// dated by their _id, edited ones carry a version and an updated_at stamp.
+func (r *genreRepositoryImpl) State(ctx context.Context) (*models.CollectionState, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"count":         bson.M{"$sum": 1},
+			"revision":      bson.M{"$sum": bson.M{"$add": bson.A{1, bson.M{"$ifNull": bson.A{"$version", 0}}}}},
+			"last_modified": bson.M{"$max": bson.M{"$max": bson.A{bson.M{"$toDate": "$_id"}, "$updated_at"}}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	state := &models.CollectionState{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(state); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, cursor.Err()
+}
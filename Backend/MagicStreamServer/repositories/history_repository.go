@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// WatchHistoryRepository defines the interface for watch history data operations
+type WatchHistoryRepository interface {
+	SaveProgress(ctx context.Context, userID string, profileID bson.ObjectID, movieID bson.ObjectID, req *models.WatchProgressRequest, completed bool, minInterval time.Duration) (bool, error)
+	FindByProfile(ctx context.Context, profileID bson.ObjectID, inProgressOnly bool, limit, skip int64) ([]models.WatchHistory, int64, error)
+	DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error)
+	DeleteByProfile(ctx context.Context, profileID bson.ObjectID) error
+}
+
+// watchHistoryRepositoryImpl implements WatchHistoryRepository
+type watchHistoryRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewWatchHistoryRepository creates a new watch history repository
+func NewWatchHistoryRepository(collection *mongo.Collection) WatchHistoryRepository {
+	return &watchHistoryRepositoryImpl{
+		collection: collection,
+	}
+}
+
+// SaveProgress records a progress report and reports whether it was written.
+//
+// Players report every few seconds, so reports are coalesced: while a movie is
+// in progress on the same device, a report arriving within minInterval of the
+// last write is dropped. A completing report is always written, once per
+// viewing, and marks the movie watched. Dropped reports surface as a duplicate
+// key on the upsert, which the unique (profile_id, movie_id) index guarantees.
+func (r *watchHistoryRepositoryImpl) SaveProgress(ctx context.Context, userID string, profileID bson.ObjectID, movieID bson.ObjectID, req *models.WatchProgressRequest, completed bool, minInterval time.Duration) (bool, error) {
+	now := time.Now()
+	position := min(req.PositionSeconds, req.DurationSeconds)
+
+	set := bson.M{
+		"position_seconds": position,
+		"duration_seconds": req.DurationSeconds,
+		"progress":         math.Round(float64(position)/float64(req.DurationSeconds)*1000) / 1000,
+		"device":           req.Device,
+		"in_progress":      !completed,
+		"last_watched_at":  now,
+	}
+	setOnInsert := bson.M{"user_id": userID, "first_watched_at": now}
+
+	filter := bson.M{"profile_id": profileID, "movie_id": movieID}
+	update := bson.M{"$set": set, "$setOnInsert": setOnInsert}
+
+	if completed {
+		filter["in_progress"] = bson.M{"$ne": false}
+		set["watched"] = true
+		set["last_completed_at"] = now
+		update["$inc"] = bson.M{"watch_count": 1}
+	} else {
+		filter["$or"] = bson.A{
+			bson.M{"last_watched_at": bson.M{"$lte": now.Add(-minInterval)}},
+			bson.M{"in_progress": false},
+			bson.M{"device": bson.M{"$ne": req.Device}},
+		}
+		setOnInsert["watched"] = false
+		setOnInsert["watch_count"] = 0
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// FindByProfile lists a profile's history, most recently watched first
+func (r *watchHistoryRepositoryImpl) FindByProfile(ctx context.Context, profileID bson.ObjectID, inProgressOnly bool, limit, skip int64) ([]models.WatchHistory, int64, error) {
+	filter := bson.M{"profile_id": profileID}
+	if inProgressOnly {
+		filter["in_progress"] = true
+		filter["position_seconds"] = bson.M{"$gt": 0}
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.D{{Key: "last_watched_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.WatchHistory{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// DeleteByMovies removes the given movies from every profile's history
+func (r *watchHistoryRepositoryImpl) DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error) {
+	if len(movieIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"movie_id": bson.M{"$in": movieIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// DeleteByProfile clears the history of a deleted profile
+func (r *watchHistoryRepositoryImpl) DeleteByProfile(ctx context.Context, profileID bson.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"profile_id": profileID})
+	return err
+}
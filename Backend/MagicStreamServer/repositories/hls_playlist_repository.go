@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrPlaylistNotFound = errors.New("playlist not found")
+)
+
+// HLSPlaylistRepository defines the interface for HLS segment list data operations
+type HLSPlaylistRepository interface {
+	Create(ctx context.Context, playlist *models.HLSPlaylist) error
+	FindByID(ctx context.Context, id bson.ObjectID) (*models.HLSPlaylist, error)
+	Delete(ctx context.Context, id bson.ObjectID) error
+	FindByMovies(ctx context.Context, movieIDs []bson.ObjectID) ([]models.HLSPlaylist, error)
+	DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error)
+}
+
+// hlsPlaylistRepositoryImpl implements HLSPlaylistRepository
+type hlsPlaylistRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewHLSPlaylistRepository creates a new HLS playlist repository
+func NewHLSPlaylistRepository(collection *mongo.Collection) HLSPlaylistRepository {
+	return &hlsPlaylistRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *hlsPlaylistRepositoryImpl) Create(ctx context.Context, playlist *models.HLSPlaylist) error {
+	_, err := r.collection.InsertOne(ctx, playlist)
+	return err
+}
+
+func (r *hlsPlaylistRepositoryImpl) FindByID(ctx context.Context, id bson.ObjectID) (*models.HLSPlaylist, error) {
+	var playlist models.HLSPlaylist
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&playlist)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, err
+	}
+
+	return &playlist, nil
+}
+
+func (r *hlsPlaylistRepositoryImpl) Delete(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrPlaylistNotFound
+	}
+
+	return nil
+}
+
+// DeleteByMovies removes the playlists of purged movies
+// FindByMovies lists every playlist of the given movies, including replaced
+// ones whose cleanup hasn't run yet
+func (r *hlsPlaylistRepositoryImpl) FindByMovies(ctx context.Context, movieIDs []bson.ObjectID) ([]models.HLSPlaylist, error) {
+	if len(movieIDs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"movie_id": bson.M{"$in": movieIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var playlists []models.HLSPlaylist
+	if err := cursor.All(ctx, &playlists); err != nil {
+		return nil, err
+	}
+
+	return playlists, nil
+}
+
+func (r *hlsPlaylistRepositoryImpl) DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error) {
+	if len(movieIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"movie_id": bson.M{"$in": movieIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
@@ -3,6 +3,10 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -13,27 +17,61 @@ import (
 var (
 	ErrMovieNotFound      = errors.New("movie not found")
 	ErrMovieAlreadyExists = errors.New("movie already exists")
+	ErrVersionConflict    = errors.New("movie was modified by someone else")
 )
 
-// MovieRepository defines the interface for movie data operations
+// MovieRepository defines the interface for movie data operations. Reads leave
+// out trashed movies and those above the maturity limit of the context.
 type MovieRepository interface {
 	Create(ctx context.Context, movie *models.Movie) error
-	FindAll(ctx context.Context, filter bson.M, opts []*options.FindOptions) ([]models.Movie, error)
+	FindAll(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.Movie, error)
 	FindByID(ctx context.Context, id string) (*models.Movie, error)
 	FindByImdbID(ctx context.Context, imdbID string) (*models.Movie, error)
 	FindByGenre(ctx context.Context, genreID int, limit, skip int) ([]models.Movie, error)
-	FindByGenres(ctx context.Context, genreIDs []int, limit int) ([]models.Movie, error)
+	FindByGenres(ctx context.Context, genreIDs []int, limit, skip int) ([]models.Movie, error)
 	Update(ctx context.Context, id string, update bson.M) error
-	Delete(ctx context.Context, id string) error
+	UpdateVersioned(ctx context.Context, id string, expectedVersion int64, update bson.M) error
+	Delete(ctx context.Context, id string, deletedBy string) error
+	Restore(ctx context.Context, id string) error
+	FindDeleted(ctx context.Context, limit, skip int64) ([]models.Movie, int64, error)
+	FindDeletedByID(ctx context.Context, id string) (*models.Movie, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) ([]models.Movie, error)
 	Count(ctx context.Context, filter bson.M) (int64, error)
 	MovieExists(ctx context.Context, imdbID string) (bool, error)
+	Facets(ctx context.Context, genreFilter, rankingFilter bson.M) (*models.MovieFacets, error)
+	FindByPerson(ctx context.Context, personID bson.ObjectID, limit, skip int64) ([]models.Movie, int64, error)
+	RenameCreditPerson(ctx context.Context, personID bson.ObjectID, name string) error
+	ReassignCredits(ctx context.Context, fromIDs []bson.ObjectID, to *models.Person) error
+	UpsertByImdbID(ctx context.Context, movie *models.Movie) (bool, error)
+	ForEach(ctx context.Context, filter bson.M, fn func(movie *models.Movie) error) error
+	IsPersonCredited(ctx context.Context, personID bson.ObjectID) (bool, error)
+	SyncRanking(ctx context.Context, ranking *models.Ranking) error
+	SyncGenre(ctx context.Context, genre *models.Genre) error
+	Search(ctx context.Context, query string, filter bson.M, limit, skip int64) ([]models.Movie, int64, error)
+	Autocomplete(ctx context.Context, prefix string, limit int64) ([]models.Movie, error)
+	IsRankingAssigned(ctx context.Context, value int) (bool, error)
+	ApplyRatingChange(ctx context.Context, movieID bson.ObjectID, oldRating, newRating int) error
+	State(ctx context.Context) (*models.CollectionState, error)
 }
 
 // movieRepositoryImpl implements MovieRepository
 type movieRepositoryImpl struct {
 	collection *mongo.Collection
+
+	// State is read on every anonymous list request, so it is kept briefly.
+	// Writes through the repository clear it; stateGen tells a state computed
+	// before such a write from a current one.
+	stateMu       sync.Mutex
+	state         *models.CollectionState
+	stateExpireAt time.Time
+	stateGen      uint64
 }
 
+// movieStateTTL is how long a computed collection state is reused. It bounds
+// how long writes made by other server instances or outside the repository
+// take to show in list validators.
+const movieStateTTL = 5 * time.Second
+
 // NewMovieRepository creates a new movie repository
 func NewMovieRepository(collection *mongo.Collection) MovieRepository {
 	return &movieRepositoryImpl{
@@ -41,7 +79,87 @@ func NewMovieRepository(collection *mongo.Collection) MovieRepository {
 	}
 }
 
+// withVersionBump adds a version increment and an updated_at stamp to an update
+// document so every write to a movie moves its version forward
+func withVersionBump(update bson.M) bson.M {
+	bumped := bson.M{}
+	for k, v := range update {
+		bumped[k] = v
+	}
+
+	inc, _ := bumped["$inc"].(bson.M)
+	if inc == nil {
+		inc = bson.M{}
+	}
+	inc["version"] = 1
+	bumped["$inc"] = inc
+
+	currentDate, _ := bumped["$currentDate"].(bson.M)
+	if currentDate == nil {
+		currentDate = bson.M{}
+	}
+	currentDate["updated_at"] = true
+	bumped["$currentDate"] = currentDate
+
+	return bumped
+}
+
+// activeFilter copies the filter and excludes soft-deleted movies.
+// Every read path goes through it, via catalogFilter, so trashed movies never
+// leak into the catalog.
+func activeFilter(filter bson.M) bson.M {
+	active := bson.M{"deleted_at": nil}
+	for k, v := range filter {
+		active[k] = v
+	}
+	return active
+}
+
+type maturityLimitKey struct{}
+
+// WithMaturityLimit returns a context whose movie reads leave out movies rated
+// above max, the limit of the viewer they are made for. An empty max is a
+// viewer without a limit.
+func WithMaturityLimit(ctx context.Context, max string) context.Context {
+	return context.WithValue(ctx, maturityLimitKey{}, max)
+}
+
+// WithoutMaturityLimit returns a context whose movie reads see the whole
+// catalog, for admin endpoints and background jobs
+func WithoutMaturityLimit(ctx context.Context) context.Context {
+	return WithMaturityLimit(ctx, "")
+}
+
+// catalogFilter is activeFilter for reads, which also hides movies above the
+// maturity limit carried by ctx. The limit lives here rather than in handlers
+// so no read path can forget it: a context that states no limit, not even
+// WithoutMaturityLimit, gets the strictest one.
+func catalogFilter(ctx context.Context, filter bson.M) bson.M {
+	visible := activeFilter(filter)
+
+	max, stated := ctx.Value(maturityLimitKey{}).(string)
+	if !stated {
+		max = models.CertificationG
+	}
+	if max == "" {
+		return visible
+	}
+
+	// An unknown limit matches nothing rather than everything
+	allowed := models.CertificationsUpTo(max)
+	if allowed == nil {
+		allowed = []string{}
+	}
+
+	// Combined with $and so a certification filter of the caller still applies
+	and, _ := visible["$and"].(bson.A)
+	visible["$and"] = append(append(bson.A{}, and...), bson.M{"age_certification": bson.M{"$in": allowed}})
+	return visible
+}
+
 func (r *movieRepositoryImpl) Create(ctx context.Context, movie *models.Movie) error {
+	defer r.resetState()
+
 	// Check if movie already exists
 	exists, err := r.MovieExists(ctx, movie.ImdbID)
 	if err != nil {
@@ -51,18 +169,20 @@ func (r *movieRepositoryImpl) Create(ctx context.Context, movie *models.Movie) e
 		return ErrMovieAlreadyExists
 	}
 
+	movie.Version = 1
+	movie.UpdatedAt = time.Now()
 	_, err = r.collection.InsertOne(ctx, movie)
 	return err
 }
 
-func (r *movieRepositoryImpl) FindAll(ctx context.Context, filter bson.M, opts []*options.FindOptions) ([]models.Movie, error) {
-	cursor, err := r.collection.Find(ctx, filter)
+func (r *movieRepositoryImpl) FindAll(ctx context.Context, filter bson.M, opts ...options.Lister[options.FindOptions]) ([]models.Movie, error) {
+	cursor, err := r.collection.Find(ctx, catalogFilter(ctx, filter), opts...)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var movies []models.Movie
+	movies := []models.Movie{}
 	if err := cursor.All(ctx, &movies); err != nil {
 		return nil, err
 	}
@@ -77,7 +197,7 @@ func (r *movieRepositoryImpl) FindByID(ctx context.Context, id string) (*models.
 	}
 
 	var movie models.Movie
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&movie)
+	err = r.collection.FindOne(ctx, catalogFilter(ctx, bson.M{"_id": objectID})).Decode(&movie)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, ErrMovieNotFound
@@ -90,7 +210,7 @@ func (r *movieRepositoryImpl) FindByID(ctx context.Context, id string) (*models.
 
 func (r *movieRepositoryImpl) FindByImdbID(ctx context.Context, imdbID string) (*models.Movie, error) {
 	var movie models.Movie
-	err := r.collection.FindOne(ctx, bson.M{"imdb_id": imdbID}).Decode(&movie)
+	err := r.collection.FindOne(ctx, catalogFilter(ctx, bson.M{"imdb_id": imdbID})).Decode(&movie)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, ErrMovieNotFound
@@ -103,37 +223,86 @@ func (r *movieRepositoryImpl) FindByImdbID(ctx context.Context, imdbID string) (
 
 func (r *movieRepositoryImpl) FindByGenre(ctx context.Context, genreID int, limit, skip int) ([]models.Movie, error) {
 	filter := bson.M{"genre.genre_id": genreID}
-	opts := []*options.FindOptions{
-		{
-			Limit: &[]int64{int64(limit)}[0],
-			Skip:  &[]int64{int64(skip)}[0],
-			Sort:  bson.M{"ranking.ranking_value": -1},
-		},
-	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"ranking.order": 1}) // best ranked first
 
 	return r.FindAll(ctx, filter, opts)
 }
 
-func (r *movieRepositoryImpl) FindByGenres(ctx context.Context, genreIDs []int, limit int) ([]models.Movie, error) {
+func (r *movieRepositoryImpl) FindByGenres(ctx context.Context, genreIDs []int, limit, skip int) ([]models.Movie, error) {
 	filter := bson.M{"genre.genre_id": bson.M{"$in": genreIDs}}
-	opts := []*options.FindOptions{
-		{
-			Limit: &[]int64{int64(limit)}[0],
-			Sort:  bson.M{"ranking.ranking_value": -1},
-		},
-	}
+	opts := options.Find().
+		SetLimit(int64(limit)).
+		SetSkip(int64(skip)).
+		SetSort(bson.M{"ranking.order": 1}) // best ranked first
 
 	return r.FindAll(ctx, filter, opts)
 }
 
 func (r *movieRepositoryImpl) Update(ctx context.Context, id string, update bson.M) error {
+	defer r.resetState()
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := activeFilter(bson.M{"_id": objectID})
+	result, err := r.collection.UpdateOne(ctx, filter, withVersionBump(update))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrMovieNotFound
+	}
+
+	return nil
+}
+
+// UpdateVersioned applies the update only while the movie is still at the
+// expected version, returning ErrVersionConflict when someone else wrote first
+func (r *movieRepositoryImpl) UpdateVersioned(ctx context.Context, id string, expectedVersion int64, update bson.M) error {
+	defer r.resetState()
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := activeFilter(bson.M{"_id": objectID, "version": expectedVersion})
+	result, err := r.collection.UpdateOne(ctx, filter, withVersionBump(update))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		exists, err := r.collection.CountDocuments(ctx, activeFilter(bson.M{"_id": objectID}))
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return ErrVersionConflict
+		}
+		return ErrMovieNotFound
+	}
+
+	return nil
+}
+
+// Delete moves a movie to the trash; PurgeDeleted removes it for good later
+func (r *movieRepositoryImpl) Delete(ctx context.Context, id string, deletedBy string) error {
+	defer r.resetState()
+
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objectID}
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now(), "deleted_by": deletedBy}}
+	result, err := r.collection.UpdateOne(ctx, activeFilter(bson.M{"_id": objectID}), withVersionBump(update))
 	if err != nil {
 		return err
 	}
@@ -145,29 +314,555 @@ func (r *movieRepositoryImpl) Update(ctx context.Context, id string, update bson
 	return nil
 }
 
-func (r *movieRepositoryImpl) Delete(ctx context.Context, id string) error {
+// Restore takes a movie back out of the trash
+func (r *movieRepositoryImpl) Restore(ctx context.Context, id string) error {
+	defer r.resetState()
+
 	objectID, err := bson.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	filter := bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{"$unset": bson.M{"deleted_at": "", "deleted_by": ""}}
+	result, err := r.collection.UpdateOne(ctx, filter, withVersionBump(update))
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
+	if result.MatchedCount == 0 {
 		return ErrMovieNotFound
 	}
 
 	return nil
 }
 
+// FindDeleted lists trashed movies, most recently deleted first
+func (r *movieRepositoryImpl) FindDeleted(ctx context.Context, limit, skip int64) ([]models.Movie, int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$ne": nil}}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.M{"deleted_at": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	movies := []models.Movie{}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, 0, err
+	}
+
+	return movies, total, nil
+}
+
+// FindDeletedByID looks up a single trashed movie
+func (r *movieRepositoryImpl) FindDeletedByID(ctx context.Context, id string) (*models.Movie, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var movie models.Movie
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}).Decode(&movie)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrMovieNotFound
+		}
+		return nil, err
+	}
+
+	return &movie, nil
+}
+
+// PurgeDeleted permanently removes movies trashed before the given time and
+// returns them, so data kept about them elsewhere can be cleaned up too. Each
+// movie is removed on its own, so one restored meanwhile is neither removed
+// nor returned.
+func (r *movieRepositoryImpl) PurgeDeleted(ctx context.Context, deletedBefore time.Time) ([]models.Movie, error) {
+	defer r.resetState()
+
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []struct {
+		ID bson.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return nil, err
+	}
+
+	var purged []models.Movie
+	for _, candidate := range candidates {
+		var movie models.Movie
+		err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": candidate.ID, "deleted_at": filter["deleted_at"]}).Decode(&movie)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged = append(purged, movie)
+	}
+
+	return purged, nil
+}
+
 func (r *movieRepositoryImpl) Count(ctx context.Context, filter bson.M) (int64, error) {
-	return r.collection.CountDocuments(ctx, filter)
+	return r.collection.CountDocuments(ctx, catalogFilter(ctx, filter))
 }
 
+// MovieExists also sees trashed movies, since the IMDb ID stays taken until purged
 func (r *movieRepositoryImpl) MovieExists(ctx context.Context, imdbID string) (bool, error) {
 	count, err := r.collection.CountDocuments(ctx, bson.M{"imdb_id": imdbID})
 	return count > 0, err
-}
\ No newline at end of file
+}
+
+// Facets counts movies per genre and per ranking in a single $facet aggregation.
+// Each facet is computed without its own filter so the counts of sibling values
+// stay visible once one of them is selected.
+func (r *movieRepositoryImpl) Facets(ctx context.Context, genreFilter, rankingFilter bson.M) (*models.MovieFacets, error) {
+	combined := bson.M{}
+	for k, v := range genreFilter {
+		combined[k] = v
+	}
+	for k, v := range rankingFilter {
+		combined[k] = v
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: catalogFilter(ctx, bson.M{})}},
+		{{Key: "$facet", Value: bson.M{
+			"genres": bson.A{
+				bson.M{"$match": rankingFilter},
+				bson.M{"$unwind": "$genre"},
+				bson.M{"$group": bson.M{
+					"_id":   "$genre.genre_id",
+					"name":  bson.M{"$first": "$genre.genre_name"},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "name": 1, "count": 1}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "value", Value: 1}}},
+			},
+			"rankings": bson.A{
+				bson.M{"$match": genreFilter},
+				bson.M{"$group": bson.M{
+					"_id":   "$ranking.ranking_value",
+					"name":  bson.M{"$first": "$ranking.ranking_name"},
+					"order": bson.M{"$first": "$ranking.order"},
+					"count": bson.M{"$sum": 1},
+				}},
+				bson.M{"$sort": bson.D{{Key: "order", Value: 1}, {Key: "_id", Value: 1}}},
+				bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "name": 1, "count": 1}},
+			},
+			"total": bson.A{
+				bson.M{"$match": combined},
+				bson.M{"$count": "count"},
+			},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"genres":   1,
+			"rankings": 1,
+			"total":    bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$total.count", 0}}, 0}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	facets := &models.MovieFacets{
+		Genres:   []models.FacetBucket{},
+		Rankings: []models.FacetBucket{},
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(facets); err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, cursor.Err()
+}
+
+// FindByPerson returns the movies crediting a person, newest release first
+func (r *movieRepositoryImpl) FindByPerson(ctx context.Context, personID bson.ObjectID, limit, skip int64) ([]models.Movie, int64, error) {
+	filter := catalogFilter(ctx, bson.M{"credits.person_id": personID})
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.D{{Key: "release_date", Value: -1}, {Key: "title", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	movies := []models.Movie{}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, 0, err
+	}
+
+	return movies, total, nil
+}
+
+// RenameCreditPerson and ReassignCredits deliberately include trashed movies so
+// their credits are still correct if they are restored.
+
+// RenameCreditPerson refreshes the denormalized name on every credit of a person
+func (r *movieRepositoryImpl) RenameCreditPerson(ctx context.Context, personID bson.ObjectID, name string) error {
+	defer r.resetState()
+
+	filter := bson.M{"credits.person_id": personID}
+	update := bson.M{"$set": bson.M{"credits.$[c].name": name}}
+	opts := options.UpdateMany().SetArrayFilters([]any{bson.M{"c.person_id": personID}})
+
+	_, err := r.collection.UpdateMany(ctx, filter, withVersionBump(update), opts)
+	return err
+}
+
+// ReassignCredits points every credit of the given people at another person.
+// A movie crediting several of them, or the person too, for the same job and
+// character keeps only the first such credit. Each movie is rewritten in one
+// atomic update, and running it again changes nothing.
+func (r *movieRepositoryImpl) ReassignCredits(ctx context.Context, fromIDs []bson.ObjectID, to *models.Person) error {
+	defer r.resetState()
+
+	filter := bson.M{"credits.person_id": bson.M{"$in": fromIDs}}
+
+	reassigned := bson.M{"$map": bson.M{
+		"input": "$credits",
+		"as":    "c",
+		"in": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{"$$c.person_id", fromIDs}},
+			bson.M{"$mergeObjects": bson.A{"$$c", bson.M{"person_id": to.ID, "name": to.Name}}},
+			"$$c",
+		}},
+	}}
+	creditKey := func(credit string) bson.M {
+		return bson.M{"person_id": credit + ".person_id", "job": credit + ".job", "character": credit + ".character"}
+	}
+	deduplicated := bson.M{"$reduce": bson.M{
+		"input":        "$credits",
+		"initialValue": bson.A{},
+		"in": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{
+				creditKey("$$this"),
+				bson.M{"$map": bson.M{"input": "$$value", "as": "kept", "in": creditKey("$$kept")}},
+			}},
+			"$$value",
+			bson.M{"$concatArrays": bson.A{"$$value", bson.A{"$$this"}}},
+		}},
+	}}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"credits": reassigned}}},
+		{{Key: "$set", Value: bson.M{
+			"credits":    deduplicated,
+			"version":    bson.M{"$add": bson.A{"$version", 1}},
+			"updated_at": "$$NOW",
+		}}},
+	}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// UpsertByImdbID inserts the movie or replaces the fields of the movie with the
+// same IMDb ID, reporting whether a new document was created
+func (r *movieRepositoryImpl) UpsertByImdbID(ctx context.Context, movie *models.Movie) (bool, error) {
+	defer r.resetState()
+
+	fields, err := bson.Marshal(movie)
+	if err != nil {
+		return false, err
+	}
+
+	var set bson.M
+	if err := bson.Unmarshal(fields, &set); err != nil {
+		return false, err
+	}
+	delete(set, "_id")
+	delete(set, "version")
+	delete(set, "updated_at")
+	delete(set, "user_rating")
+	// Uploaded through their own endpoints
+	delete(set, "videos")
+	delete(set, "renditions")
+	delete(set, "subtitles")
+
+	// Ratings belong to users, imports only seed them on new movies
+	update := withVersionBump(bson.M{"$set": set})
+	setOnInsert := bson.M{"user_rating": models.NewRatingSummary()}
+	if !movie.ID.IsZero() {
+		setOnInsert["_id"] = movie.ID
+	}
+	update["$setOnInsert"] = setOnInsert
+	if movie.Poster == nil {
+		// An uploaded poster only survives while poster_path still points at it
+		update["$unset"] = bson.M{"poster": ""}
+	}
+
+	opts := options.UpdateOne().SetUpsert(true)
+	result, err := r.collection.UpdateOne(ctx, bson.M{"imdb_id": movie.ImdbID}, update, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
+// ForEach streams every movie matching the filter to fn, one document at a time,
+// so large exports run in constant memory. Iteration stops at the first error.
+func (r *movieRepositoryImpl) ForEach(ctx context.Context, filter bson.M, fn func(movie *models.Movie) error) error {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+
+	cursor, err := r.collection.Find(ctx, catalogFilter(ctx, filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var movie models.Movie
+		if err := cursor.Decode(&movie); err != nil {
+			return err
+		}
+		if err := fn(&movie); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// IsPersonCredited reports whether any movie, trashed or not, credits the person
+func (r *movieRepositoryImpl) IsPersonCredited(ctx context.Context, personID bson.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"credits.person_id": personID})
+	return count > 0, err
+}
+
+// SyncRanking copies a ranking's name and order onto every movie given that
+// ranking, trash included, so sorting and filtering follow the catalog
+func (r *movieRepositoryImpl) SyncRanking(ctx context.Context, ranking *models.Ranking) error {
+	defer r.resetState()
+
+	filter := bson.M{"ranking.ranking_value": ranking.RankingValue}
+	update := bson.M{"$set": bson.M{
+		"ranking.ranking_name": ranking.RankingName,
+		"ranking.order":        ranking.Order,
+	}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, withVersionBump(update))
+	return err
+}
+
+// SyncGenre copies a genre's catalog name and translations onto every movie in
+// that genre, trash included, so localized genre names follow the catalog
+func (r *movieRepositoryImpl) SyncGenre(ctx context.Context, genre *models.Genre) error {
+	defer r.resetState()
+
+	filter := bson.M{"genre.genre_id": genre.GenreID}
+	set := bson.M{"genre.$[g].genre_name": genre.GenreName}
+	update := bson.M{"$set": set}
+	if len(genre.Translations) == 0 {
+		update["$unset"] = bson.M{"genre.$[g].translations": ""}
+	} else {
+		set["genre.$[g].translations"] = genre.Translations
+	}
+	opts := options.UpdateMany().SetArrayFilters([]any{bson.M{"g.genre_id": genre.GenreID}})
+
+	_, err := r.collection.UpdateMany(ctx, filter, withVersionBump(update), opts)
+	return err
+}
+
+// Search runs a full-text query over the titles and synopses of every locale,
+// best matches first and ties broken by ranking
+func (r *movieRepositoryImpl) Search(ctx context.Context, query string, filter bson.M, limit, skip int64) ([]models.Movie, int64, error) {
+	filter = catalogFilter(ctx, filter)
+	filter["$text"] = bson.M{"$search": query}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.D{
+			{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			{Key: "ranking.order", Value: 1},
+		})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	movies := []models.Movie{}
+	if err := cursor.All(ctx, &movies); err != nil {
+		return nil, 0, err
+	}
+
+	return movies, total, nil
+}
+
+// Autocomplete returns the best ranked movies with a word in any locale's title
+// starting with the folded prefix
+func (r *movieRepositoryImpl) Autocomplete(ctx context.Context, prefix string, limit int64) ([]models.Movie, error) {
+	filter := bson.M{"search.prefixes": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	opts := options.Find().
+		SetLimit(limit).
+		SetSort(bson.D{{Key: "ranking.order", Value: 1}, {Key: "_id", Value: 1}})
+
+	return r.FindAll(ctx, filter, opts)
+}
+
+// IsRankingAssigned reports whether any movie, trash included, has the ranking
+func (r *movieRepositoryImpl) IsRankingAssigned(ctx context.Context, value int) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"ranking.ranking_value": value})
+	return count > 0, err
+}
+
+// ApplyRatingChange folds one review write into a movie's rating summary in a
+// single atomic update. oldRating is 0 for a new review, newRating is 0 for a
+// deleted one.
+func (r *movieRepositoryImpl) ApplyRatingChange(ctx context.Context, movieID bson.ObjectID, oldRating, newRating int) error {
+	defer r.resetState()
+
+	var count int
+	switch {
+	case oldRating == 0 && newRating > 0:
+		count = 1
+	case oldRating > 0 && newRating == 0:
+		count = -1
+	}
+
+	add := func(field string, delta int) bson.M {
+		return bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, delta}}
+	}
+
+	set := bson.M{
+		"user_rating.count": add("user_rating.count", count),
+		"user_rating.sum":   add("user_rating.sum", newRating-oldRating),
+		"version":           add("version", 1),
+		"updated_at":        "$$NOW",
+	}
+	if oldRating != newRating {
+		if oldRating > 0 {
+			field := fmt.Sprintf("user_rating.histogram.%d", oldRating)
+			set[field] = add(field, -1)
+		}
+		if newRating > 0 {
+			field := fmt.Sprintf("user_rating.histogram.%d", newRating)
+			set[field] = add(field, 1)
+		}
+	}
+
+	pipeline := bson.A{
+		bson.M{"$set": set},
+		bson.M{"$set": bson.M{"user_rating.average": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$user_rating.count", 0}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$user_rating.sum", "$user_rating.count"}}, 2}},
+			0,
+		}}}},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, activeFilter(bson.M{"_id": movieID}), pipeline)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrMovieNotFound
+	}
+
+	return nil
+}
+
+// State sums up the whole collection, trash included, for cache validators.
+// Every write bumps a document version, so the version total is a change counter.
+// The sum scans the collection, so it is reused until a write or movieStateTTL.
+func (r *movieRepositoryImpl) State(ctx context.Context) (*models.CollectionState, error) {
+	r.stateMu.Lock()
+	if r.state != nil && time.Now().Before(r.stateExpireAt) {
+		state := *r.state
+		r.stateMu.Unlock()
+		return &state, nil
+	}
+	gen := r.stateGen
+	r.stateMu.Unlock()
+
+	state, err := r.computeState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.stateMu.Lock()
+	if r.stateGen == gen {
+		r.state = state
+		r.stateExpireAt = time.Now().Add(movieStateTTL)
+	}
+	r.stateMu.Unlock()
+
+	copied := *state
+	return &copied, nil
+}
+
+// resetState drops the cached state after a write, so the next read sees it
+func (r *movieRepositoryImpl) resetState() {
+	r.stateMu.Lock()
+	r.state = nil
+	r.stateGen++
+	r.stateMu.Unlock()
+}
+
+func (r *movieRepositoryImpl) computeState(ctx context.Context) (*models.CollectionState, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"count":         bson.M{"$sum": 1},
+			"revision":      bson.M{"$sum": "$version"},
+			"last_modified": bson.M{"$max": "$updated_at"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	state := &models.CollectionState{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(state); err != nil {
+			return nil, err
+		}
+	}
+
+	return state, cursor.Err()
+}
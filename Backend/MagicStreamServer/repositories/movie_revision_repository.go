@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrRevisionExists   = errors.New("revision number already taken")
+)
+
+// MovieRevisionRepository defines the interface for movie history data operations
+type MovieRevisionRepository interface {
+	Create(ctx context.Context, revision *models.MovieRevision) error
+	FindByMovie(ctx context.Context, movieID bson.ObjectID, limit, skip int64) ([]models.MovieRevision, int64, error)
+	FindByNumber(ctx context.Context, movieID bson.ObjectID, revision int) (*models.MovieRevision, error)
+	LatestNumber(ctx context.Context, movieID bson.ObjectID) (int, error)
+}
+
+// movieRevisionRepositoryImpl implements MovieRevisionRepository
+type movieRevisionRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewMovieRevisionRepository creates a new movie revision repository
+func NewMovieRevisionRepository(collection *mongo.Collection) MovieRevisionRepository {
+	return &movieRevisionRepositoryImpl{
+		collection: collection,
+	}
+}
+
+// Create inserts a revision; revision numbers are unique per movie
+func (r *movieRevisionRepositoryImpl) Create(ctx context.Context, revision *models.MovieRevision) error {
+	_, err := r.collection.InsertOne(ctx, revision)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRevisionExists
+	}
+	return err
+}
+
+// FindByMovie lists a movie's revisions, newest first
+func (r *movieRevisionRepositoryImpl) FindByMovie(ctx context.Context, movieID bson.ObjectID, limit, skip int64) ([]models.MovieRevision, int64, error) {
+	filter := bson.M{"movie_id": movieID}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.M{"revision": -1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []models.MovieRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+func (r *movieRevisionRepositoryImpl) FindByNumber(ctx context.Context, movieID bson.ObjectID, revision int) (*models.MovieRevision, error) {
+	var found models.MovieRevision
+	err := r.collection.FindOne(ctx, bson.M{"movie_id": movieID, "revision": revision}).Decode(&found)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return &found, nil
+}
+
+// LatestNumber returns the highest revision number of a movie, or 0 when it has no history
+func (r *movieRevisionRepositoryImpl) LatestNumber(ctx context.Context, movieID bson.ObjectID) (int, error) {
+	opts := options.FindOne().
+		SetSort(bson.M{"revision": -1}).
+		SetProjection(bson.M{"revision": 1})
+
+	var latest models.MovieRevision
+	err := r.collection.FindOne(ctx, bson.M{"movie_id": movieID}, opts).Decode(&latest)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return latest.Revision, nil
+}
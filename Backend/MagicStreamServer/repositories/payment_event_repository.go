@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PaymentEventRepository defines the interface for the processed payment webhook events
+type PaymentEventRepository interface {
+	Exists(ctx context.Context, eventID string) (bool, error)
+	Record(ctx context.Context, event *models.ProcessedPaymentEvent) error
+}
+
+// paymentEventRepositoryImpl implements PaymentEventRepository
+type paymentEventRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewPaymentEventRepository creates a new payment event repository
+func NewPaymentEventRepository(collection *mongo.Collection) PaymentEventRepository {
+	return &paymentEventRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *paymentEventRepositoryImpl) Exists(ctx context.Context, eventID string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": eventID})
+	return count > 0, err
+}
+
+// Record marks an event as processed. Recording an event twice is not an
+// error, since a redelivery may race the first delivery.
+func (r *paymentEventRepositoryImpl) Record(ctx context.Context, event *models.ProcessedPaymentEvent) error {
+	_, err := r.collection.InsertOne(ctx, event)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrPersonNotFound = errors.New("person not found")
+	ErrPersonInUse    = errors.New("person is credited on one or more movies")
+)
+
+// PersonRepository defines the interface for people data operations
+type PersonRepository interface {
+	Create(ctx context.Context, person *models.Person) error
+	FindAll(ctx context.Context, nameQuery string, limit, skip int64) ([]models.Person, int64, error)
+	FindByID(ctx context.Context, id string) (*models.Person, error)
+	FindByIDs(ctx context.Context, ids []bson.ObjectID) ([]models.Person, error)
+	Update(ctx context.Context, id string, update bson.M) error
+	Delete(ctx context.Context, id string) error
+	DeleteMany(ctx context.Context, ids []bson.ObjectID) error
+}
+
+// personRepositoryImpl implements PersonRepository
+type personRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewPersonRepository creates a new person repository
+func NewPersonRepository(collection *mongo.Collection) PersonRepository {
+	return &personRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *personRepositoryImpl) Create(ctx context.Context, person *models.Person) error {
+	_, err := r.collection.InsertOne(ctx, person)
+	return err
+}
+
+func (r *personRepositoryImpl) FindAll(ctx context.Context, nameQuery string, limit, skip int64) ([]models.Person, int64, error) {
+	filter := bson.M{}
+	if nameQuery != "" {
+		filter["name"] = bson.M{"$regex": regexp.QuoteMeta(nameQuery), "$options": "i"}
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(bson.M{"name": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	people := []models.Person{}
+	if err := cursor.All(ctx, &people); err != nil {
+		return nil, 0, err
+	}
+
+	return people, total, nil
+}
+
+func (r *personRepositoryImpl) FindByID(ctx context.Context, id string) (*models.Person, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrPersonNotFound
+	}
+
+	var person models.Person
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&person)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPersonNotFound
+		}
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+func (r *personRepositoryImpl) FindByIDs(ctx context.Context, ids []bson.ObjectID) ([]models.Person, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var people []models.Person
+	if err := cursor.All(ctx, &people); err != nil {
+		return nil, err
+	}
+
+	return people, nil
+}
+
+func (r *personRepositoryImpl) Update(ctx context.Context, id string, update bson.M) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrPersonNotFound
+	}
+
+	update["updated_at"] = time.Now()
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrPersonNotFound
+	}
+
+	return nil
+}
+
+func (r *personRepositoryImpl) Delete(ctx context.Context, id string) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrPersonNotFound
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrPersonNotFound
+	}
+
+	return nil
+}
+
+func (r *personRepositoryImpl) DeleteMany(ctx context.Context, ids []bson.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
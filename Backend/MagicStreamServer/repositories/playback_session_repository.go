@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrPlaybackSessionNotFound = errors.New("playback session not found")
+)
+
+// PlaybackSessionRepository defines the interface for active stream data operations.
+// Sessions past expires_at count as ended even before MongoDB's TTL monitor removes them.
+type PlaybackSessionRepository interface {
+	Create(ctx context.Context, session *models.StreamSession) error
+	FindActiveByUser(ctx context.Context, userID string) ([]models.StreamSession, error)
+	Heartbeat(ctx context.Context, id bson.ObjectID, userID string, expiresAt time.Time) (*models.StreamSession, error)
+	Delete(ctx context.Context, id bson.ObjectID, userID string) error
+}
+
+// playbackSessionRepositoryImpl implements PlaybackSessionRepository
+type playbackSessionRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewPlaybackSessionRepository creates a new playback session repository
+func NewPlaybackSessionRepository(collection *mongo.Collection) PlaybackSessionRepository {
+	return &playbackSessionRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *playbackSessionRepositoryImpl) Create(ctx context.Context, session *models.StreamSession) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+// FindActiveByUser returns the user's live sessions, oldest first
+func (r *playbackSessionRepositoryImpl) FindActiveByUser(ctx context.Context, userID string) ([]models.StreamSession, error) {
+	filter := bson.M{"user_id": userID, "expires_at": bson.M{"$gt": time.Now()}}
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []models.StreamSession{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Heartbeat extends a live session. An expired or stopped session is not revived.
+func (r *playbackSessionRepositoryImpl) Heartbeat(ctx context.Context, id bson.ObjectID, userID string, expiresAt time.Time) (*models.StreamSession, error) {
+	now := time.Now()
+	filter := bson.M{"_id": id, "user_id": userID, "expires_at": bson.M{"$gt": now}}
+	update := bson.M{"$set": bson.M{"last_heartbeat_at": now, "expires_at": expiresAt}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var session models.StreamSession
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrPlaybackSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *playbackSessionRepositoryImpl) Delete(ctx context.Context, id bson.ObjectID, userID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrPlaybackSessionNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrRankingNotFound      = errors.New("ranking not found")
+	ErrRankingAlreadyExists = errors.New("ranking already exists")
+	ErrRankingInUse         = errors.New("ranking is assigned to one or more movies")
+)
+
+// RankingRepository defines the interface for ranking catalog operations
+type RankingRepository interface {
+	Create(ctx context.Context, ranking *models.Ranking) error
+	FindAll(ctx context.Context) ([]models.Ranking, error)
+	FindByValue(ctx context.Context, value int) (*models.Ranking, error)
+	Update(ctx context.Context, value int, name string, order int) error
+	Delete(ctx context.Context, value int) error
+}
+
+// rankingRepositoryImpl implements RankingRepository
+type rankingRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewRankingRepository creates a new ranking repository
+func NewRankingRepository(collection *mongo.Collection) RankingRepository {
+	return &rankingRepositoryImpl{
+		collection: collection,
+	}
+}
+
+func (r *rankingRepositoryImpl) Create(ctx context.Context, ranking *models.Ranking) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"ranking_value": ranking.RankingValue})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRankingAlreadyExists
+	}
+
+	_, err = r.collection.InsertOne(ctx, ranking)
+	return err
+}
+
+// FindAll returns the catalog best first
+func (r *rankingRepositoryImpl) FindAll(ctx context.Context) ([]models.Ranking, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "ranking_value", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rankings := []models.Ranking{}
+	if err := cursor.All(ctx, &rankings); err != nil {
+		return nil, err
+	}
+
+	return rankings, nil
+}
+
+func (r *rankingRepositoryImpl) FindByValue(ctx context.Context, value int) (*models.Ranking, error) {
+	var ranking models.Ranking
+	err := r.collection.FindOne(ctx, bson.M{"ranking_value": value}).Decode(&ranking)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRankingNotFound
+		}
+		return nil, err
+	}
+
+	return &ranking, nil
+}
+
+func (r *rankingRepositoryImpl) Update(ctx context.Context, value int, name string, order int) error {
+	update := bson.M{"$set": bson.M{"ranking_name": name, "order": order}}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"ranking_value": value}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrRankingNotFound
+	}
+
+	return nil
+}
+
+func (r *rankingRepositoryImpl) Delete(ctx context.Context, value int) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"ranking_value": value})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrRankingNotFound
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@ type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *models.RefreshToken) error
 	FindByToken(ctx context.Context, token string, userID string) (*models.RefreshToken, error)
 	RevokeUserTokens(ctx context.Context, userID string) error
+	RevokeProfileTokens(ctx context.Context, userID string, profileID bson.ObjectID) error
 	RevokeToken(ctx context.Context, tokenID string) error
 	CleanupExpired(ctx context.Context) error
 }
@@ -64,6 +65,14 @@ func (r *refreshTokenRepositoryImpl) RevokeUserTokens(ctx context.Context, userI
 	return err
 }
 
+func (r *refreshTokenRepositoryImpl) RevokeProfileTokens(ctx context.Context, userID string, profileID bson.ObjectID) error {
+	filter := bson.M{"user_id": userID, "profile_id": profileID, "revoked": false}
+	update := bson.M{"$set": bson.M{"revoked": true, "updated_at": time.Now()}}
+
+	_, err := r.collection.UpdateMany(ctx, filter, update)
+	return err
+}
+
 func (r *refreshTokenRepositoryImpl) RevokeToken(ctx context.Context, tokenID string) error {
 	objectID, err := bson.ObjectIDFromHex(tokenID)
 	if err != nil {
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewAlreadyExists = errors.New("user already reviewed this movie")
+)
+
+// ReviewRepository defines the interface for user review data operations
+type ReviewRepository interface {
+	Create(ctx context.Context, review *models.Review) error
+	FindByMovie(ctx context.Context, movieID bson.ObjectID, sort string, limit, skip int64) ([]models.Review, int64, error)
+	FindByID(ctx context.Context, id bson.ObjectID) (*models.Review, error)
+	FindByMovieAndUser(ctx context.Context, movieID bson.ObjectID, userID string) (*models.Review, error)
+	Update(ctx context.Context, id bson.ObjectID, req *models.ReviewRequest) (*models.Review, error)
+	Delete(ctx context.Context, id bson.ObjectID) (*models.Review, error)
+	MarkHelpful(ctx context.Context, id bson.ObjectID, userID string) error
+	RatingSummary(ctx context.Context, movieID bson.ObjectID) (models.RatingSummary, error)
+	DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error)
+}
+
+// reviewRepositoryImpl implements ReviewRepository
+type reviewRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewReviewRepository creates a new review repository
+func NewReviewRepository(collection *mongo.Collection) ReviewRepository {
+	return &reviewRepositoryImpl{
+		collection: collection,
+	}
+}
+
+// Create inserts a review; a user can review each movie once
+func (r *reviewRepositoryImpl) Create(ctx context.Context, review *models.Review) error {
+	_, err := r.collection.InsertOne(ctx, review)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrReviewAlreadyExists
+	}
+	return err
+}
+
+// FindByMovie lists a movie's reviews, most helpful or most recent first
+func (r *reviewRepositoryImpl) FindByMovie(ctx context.Context, movieID bson.ObjectID, sort string, limit, skip int64) ([]models.Review, int64, error) {
+	filter := bson.M{"movie_id": movieID}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	order := bson.D{{Key: "helpful_count", Value: -1}, {Key: "created_at", Value: -1}}
+	if sort == models.ReviewSortRecent {
+		order = bson.D{{Key: "created_at", Value: -1}}
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetSkip(skip).
+		SetSort(order)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []models.Review{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+func (r *reviewRepositoryImpl) FindByID(ctx context.Context, id bson.ObjectID) (*models.Review, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *reviewRepositoryImpl) FindByMovieAndUser(ctx context.Context, movieID bson.ObjectID, userID string) (*models.Review, error) {
+	return r.findOne(ctx, bson.M{"movie_id": movieID, "user_id": userID})
+}
+
+func (r *reviewRepositoryImpl) findOne(ctx context.Context, filter bson.M) (*models.Review, error) {
+	var review models.Review
+	err := r.collection.FindOne(ctx, filter).Decode(&review)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+// Update replaces a review and returns it as it was before. The rating
+// summary is adjusted from that rating, so concurrent edits each move the
+// rating they actually replaced.
+func (r *reviewRepositoryImpl) Update(ctx context.Context, id bson.ObjectID, req *models.ReviewRequest) (*models.Review, error) {
+	update := bson.M{"$set": bson.M{
+		"rating":     req.Rating,
+		"title":      req.Title,
+		"body":       req.Body,
+		"updated_at": time.Now(),
+	}}
+
+	var previous models.Review
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&previous)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &previous, nil
+}
+
+// Delete removes a review and returns it, with the rating it still had
+func (r *reviewRepositoryImpl) Delete(ctx context.Context, id bson.ObjectID) (*models.Review, error) {
+	var deleted models.Review
+	err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&deleted)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+
+	return &deleted, nil
+}
+
+// DeleteByMovies removes every review of the given movies
+func (r *reviewRepositoryImpl) DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error) {
+	if len(movieIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"movie_id": bson.M{"$in": movieIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// RatingSummary recomputes a movie's rating summary from its reviews
+func (r *reviewRepositoryImpl) RatingSummary(ctx context.Context, movieID bson.ObjectID) (models.RatingSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"movie_id": movieID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$rating", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return models.RatingSummary{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []struct {
+		Rating int   `bson:"_id"`
+		Count  int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return models.RatingSummary{}, err
+	}
+
+	summary := models.NewRatingSummary()
+	for _, bucket := range buckets {
+		summary.Histogram[strconv.Itoa(bucket.Rating)] = bucket.Count
+		summary.Count += bucket.Count
+		summary.Sum += int64(bucket.Rating) * bucket.Count
+	}
+	if summary.Count > 0 {
+		summary.Average = math.Round(float64(summary.Sum)/float64(summary.Count)*100) / 100
+	}
+
+	return summary, nil
+}
+
+// MarkHelpful records a user's helpful vote; voting twice is a no-op
+func (r *reviewRepositoryImpl) MarkHelpful(ctx context.Context, id bson.ObjectID, userID string) error {
+	filter := bson.M{"_id": id, "helpful_by": bson.M{"$ne": userID}}
+	update := bson.M{
+		"$addToSet": bson.M{"helpful_by": userID},
+		"$inc":      bson.M{"helpful_count": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,16 +3,24 @@ package repositories
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+
+	ErrProfileNotFound     = errors.New("profile not found")
+	ErrProfileLimitReached = errors.New("profile limit reached")
+	ErrLastProfile         = errors.New("cannot delete the last profile")
+
+	ErrSubscriptionChanged = errors.New("subscription changed since it was read")
 )
 
 // UserRepository defines the interface for user data operations
@@ -20,7 +28,14 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByEmail(ctx context.Context, email string) (*models.User, error)
 	FindByID(ctx context.Context, userID string) (*models.User, error)
-	UpdateFavoriteGenres(ctx context.Context, userID string, genres []models.Genre) error
+	UpdateFavoriteGenres(ctx context.Context, userID string, profileID bson.ObjectID, genres []models.Genre) error
+	AddProfile(ctx context.Context, userID string, profile *models.Profile, maxProfiles int) error
+	ReplaceProfile(ctx context.Context, userID string, profile *models.Profile) error
+	DeleteProfile(ctx context.Context, userID string, profileID bson.ObjectID) error
+	SetParentalControls(ctx context.Context, userID string, controls *models.ParentalControls) error
+	RecordPINFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) (*models.ParentalControls, error)
+	RecordPINSuccess(ctx context.Context, userID string) (*models.ParentalControls, error)
+	SaveSubscription(ctx context.Context, userID string, subscription, previous *models.Subscription) error
 	UserExists(ctx context.Context, email string) (bool, error)
 }
 
@@ -74,12 +89,13 @@ func (r *userRepositoryImpl) FindByID(ctx context.Context, userID string) (*mode
 	return &user, nil
 }
 
-func (r *userRepositoryImpl) UpdateFavoriteGenres(ctx context.Context, userID string, genres []models.Genre) error {
-	filter := bson.M{"user_id": userID}
+func (r *userRepositoryImpl) UpdateFavoriteGenres(ctx context.Context, userID string, profileID bson.ObjectID, genres []models.Genre) error {
+	filter := bson.M{"user_id": userID, "profiles._id": profileID}
 	update := bson.M{
 		"$set": bson.M{
-			"favourite_genres": genres,
-			"updated_at":       time.Now(),
+			"profiles.$.favourite_genres": genres,
+			"profiles.$.updated_at":       time.Now(),
+			"updated_at":                  time.Now(),
 		},
 	}
 
@@ -88,6 +104,113 @@ func (r *userRepositoryImpl) UpdateFavoriteGenres(ctx context.Context, userID st
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return ErrProfileNotFound
+	}
+
+	return nil
+}
+
+// AddProfile appends a profile unless the user already has maxProfiles. The
+// limit is part of the filter so parallel requests can't exceed it.
+func (r *userRepositoryImpl) AddProfile(ctx context.Context, userID string, profile *models.Profile, maxProfiles int) error {
+	filter := bson.M{"user_id": userID}
+	if maxProfiles > 0 {
+		filter["profiles."+strconv.Itoa(maxProfiles-1)] = bson.M{"$exists": false}
+	}
+	update := bson.M{
+		"$push": bson.M{"profiles": profile},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrUserNotFound
+		}
+		return ErrProfileLimitReached
+	}
+
+	return nil
+}
+
+// ReplaceProfile overwrites the profile with the same ID
+func (r *userRepositoryImpl) ReplaceProfile(ctx context.Context, userID string, profile *models.Profile) error {
+	filter := bson.M{"user_id": userID, "profiles._id": profile.ID}
+	update := bson.M{
+		"$set": bson.M{"profiles.$": profile, "updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrProfileNotFound
+	}
+
+	return nil
+}
+
+// DeleteProfile removes a profile, keeping at least one. When the default
+// profile goes, the next one becomes the default.
+func (r *userRepositoryImpl) DeleteProfile(ctx context.Context, userID string, profileID bson.ObjectID) error {
+	filter := bson.M{
+		"user_id":      userID,
+		"profiles._id": profileID,
+		"profiles.1":   bson.M{"$exists": true},
+	}
+	update := bson.M{
+		"$pull": bson.M{"profiles": bson.M{"_id": profileID}},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"user_id": userID, "profiles._id": profileID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrProfileNotFound
+		}
+		return ErrLastProfile
+	}
+
+	return nil
+}
+
+// SetParentalControls replaces the parental controls of a user, or removes them
+// when controls is nil
+func (r *userRepositoryImpl) SetParentalControls(ctx context.Context, userID string, controls *models.ParentalControls) error {
+	update := bson.M{
+		"$set": bson.M{"parental_controls": controls, "updated_at": time.Now()},
+	}
+	if controls == nil {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"parental_controls": ""},
+		}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"user_id": userID}, update)
+	if err != nil {
+		return err
+	}
+
 	if result.MatchedCount == 0 {
 		return ErrUserNotFound
 	}
@@ -95,6 +218,91 @@ func (r *userRepositoryImpl) UpdateFavoriteGenres(ctx context.Context, userID st
 	return nil
 }
 
+// RecordPINFailure counts a wrong parental PIN. The attempt that reaches
+// maxAttempts locks PIN checks for the lockout and starts the count over. The
+// increment is atomic so parallel guesses can't slip past the limit.
+func (r *userRepositoryImpl) RecordPINFailure(ctx context.Context, userID string, maxAttempts int, lockout time.Duration) (*models.ParentalControls, error) {
+	attempts := bson.M{"$add": bson.A{"$parental_controls.failed_attempts", 1}}
+	locks := bson.M{"$gte": bson.A{attempts, maxAttempts}}
+
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"parental_controls.failed_attempts": bson.M{"$cond": bson.A{locks, 0, attempts}},
+			"parental_controls.locked_until":    bson.M{"$cond": bson.A{locks, time.Now().Add(lockout), "$parental_controls.locked_until"}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID, "parental_controls": bson.M{"$type": "object"}}, update, opts).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user.ParentalControls, nil
+}
+
+// RecordPINSuccess starts the wrong PIN count over after a correct PIN. The
+// reset only applies while PIN checks aren't locked, so a lockout set by a
+// parallel wrong guess after the user was read still holds. The returned
+// controls are locked in that case.
+func (r *userRepositoryImpl) RecordPINSuccess(ctx context.Context, userID string) (*models.ParentalControls, error) {
+	filter := bson.M{
+		"user_id":                        userID,
+		"parental_controls":              bson.M{"$type": "object"},
+		"parental_controls.locked_until": bson.M{"$not": bson.M{"$gt": time.Now()}},
+	}
+	update := bson.M{
+		"$set":   bson.M{"parental_controls.failed_attempts": 0},
+		"$unset": bson.M{"parental_controls.locked_until": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Locked meanwhile, or the controls are gone
+		err = r.collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user)
+	}
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return user.ParentalControls, nil
+}
+
+// SaveSubscription replaces the user's subscription, provided it is still the
+// previous one that was read (nil for none). Payment events for a user are
+// applied one at a time this way.
+func (r *userRepositoryImpl) SaveSubscription(ctx context.Context, userID string, subscription, previous *models.Subscription) error {
+	filter := bson.M{"user_id": userID}
+	if previous == nil {
+		filter["subscription"] = bson.M{"$exists": false}
+	} else {
+		filter["subscription.last_event_at"] = previous.LastEventAt
+	}
+	update := bson.M{
+		"$set": bson.M{"subscription": subscription, "updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrSubscriptionChanged
+	}
+
+	return nil
+}
+
 func (r *userRepositoryImpl) UserExists(ctx context.Context, email string) (bool, error) {
 	count, err := r.collection.CountDocuments(ctx, bson.M{"email": email})
 	return count > 0, err
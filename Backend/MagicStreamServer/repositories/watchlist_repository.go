@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrWatchlistEntryNotFound = errors.New("movie is not in the watchlist")
+	ErrWatchlistEntryExists   = errors.New("movie is already in the watchlist")
+	ErrWatchlistFull          = errors.New("watchlist is full")
+)
+
+// WatchlistRepository defines the interface for watchlist data operations
+type WatchlistRepository interface {
+	Add(ctx context.Context, entry *models.WatchlistEntry) error
+	FindByProfile(ctx context.Context, profileID bson.ObjectID) ([]models.WatchlistEntry, error)
+	FindEntry(ctx context.Context, profileID bson.ObjectID, movieID bson.ObjectID) (*models.WatchlistEntry, error)
+	Reorder(ctx context.Context, profileID bson.ObjectID, movieIDs []bson.ObjectID) error
+	Remove(ctx context.Context, profileID bson.ObjectID, movieID bson.ObjectID) error
+	Contains(ctx context.Context, profileID bson.ObjectID, movieIDs []bson.ObjectID) (map[bson.ObjectID]bool, error)
+	DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error)
+	DeleteByProfile(ctx context.Context, profileID bson.ObjectID) error
+}
+
+// watchlistRepositoryImpl implements WatchlistRepository
+type watchlistRepositoryImpl struct {
+	collection *mongo.Collection
+}
+
+// NewWatchlistRepository creates a new watchlist repository
+func NewWatchlistRepository(collection *mongo.Collection) WatchlistRepository {
+	return &watchlistRepositoryImpl{
+		collection: collection,
+	}
+}
+
+// Add inserts an entry; a movie is on a profile's watchlist at most once
+func (r *watchlistRepositoryImpl) Add(ctx context.Context, entry *models.WatchlistEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrWatchlistEntryExists
+	}
+	return err
+}
+
+// FindByProfile returns the profile's whole watchlist in order
+func (r *watchlistRepositoryImpl) FindByProfile(ctx context.Context, profileID bson.ObjectID) ([]models.WatchlistEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "added_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"profile_id": profileID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.WatchlistEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *watchlistRepositoryImpl) FindEntry(ctx context.Context, profileID bson.ObjectID, movieID bson.ObjectID) (*models.WatchlistEntry, error) {
+	var entry models.WatchlistEntry
+	err := r.collection.FindOne(ctx, bson.M{"profile_id": profileID, "movie_id": movieID}).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrWatchlistEntryNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Reorder renumbers the profile's entries to follow the given movie order
+func (r *watchlistRepositoryImpl) Reorder(ctx context.Context, profileID bson.ObjectID, movieIDs []bson.ObjectID) error {
+	if len(movieIDs) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(movieIDs))
+	for position, movieID := range movieIDs {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"profile_id": profileID, "movie_id": movieID}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position}}))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *watchlistRepositoryImpl) Remove(ctx context.Context, profileID bson.ObjectID, movieID bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"profile_id": profileID, "movie_id": movieID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrWatchlistEntryNotFound
+	}
+
+	return nil
+}
+
+// Contains reports which of the given movies are on the profile's watchlist
+func (r *watchlistRepositoryImpl) Contains(ctx context.Context, profileID bson.ObjectID, movieIDs []bson.ObjectID) (map[bson.ObjectID]bool, error) {
+	saved := make(map[bson.ObjectID]bool, len(movieIDs))
+	if len(movieIDs) == 0 {
+		return saved, nil
+	}
+
+	filter := bson.M{"profile_id": profileID, "movie_id": bson.M{"$in": movieIDs}}
+	opts := options.Find().SetProjection(bson.M{"movie_id": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.WatchlistEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		saved[entry.MovieID] = true
+	}
+
+	return saved, nil
+}
+
+// DeleteByMovies removes the given movies from every watchlist
+func (r *watchlistRepositoryImpl) DeleteByMovies(ctx context.Context, movieIDs []bson.ObjectID) (int64, error) {
+	if len(movieIDs) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"movie_id": bson.M{"$in": movieIDs}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// DeleteByProfile empties the watchlist of a deleted profile
+func (r *watchlistRepositoryImpl) DeleteByProfile(ctx context.Context, profileID bson.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"profile_id": profileID})
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
 	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
@@ -19,14 +20,16 @@ import (
 // AuthHandler handles authentication related requests
 type AuthHandler struct {
 	tokenService *authservice.TokenService
+	cfg          *config.Config
 	userRepo     repositories.UserRepository
 	genreRepo    repositories.GenreRepository
 }
 
 // NewAuthHandler creates a new auth handler with dependencies injected
-func NewAuthHandler(ts *authservice.TokenService, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository) *AuthHandler {
+func NewAuthHandler(ts *authservice.TokenService, cfg *config.Config, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository) *AuthHandler {
 	return &AuthHandler{
 		tokenService: ts,
+		cfg:          cfg,
 		userRepo:     userRepo,
 		genreRepo:    genreRepo,
 	}
@@ -104,16 +107,23 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Create user
 	userID := bson.NewObjectID().Hex()
 	newUser := models.User{
-		UserID:          userID,
-		FirstName:       req.FirstName,
-		LastName:        req.LastName,
-		Email:           req.Email,
-		Password:        hashedPassword,
-		Role:            "USER",
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-		FavouriteGenres: req.FavouriteGenres,
+		UserID:    userID,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Password:  hashedPassword,
+		Role:      "USER",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Profiles: []models.Profile{{
+			ID:              bson.NewObjectID(),
+			Name:            req.FirstName,
+			FavouriteGenres: req.FavouriteGenres,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}},
 	}
+	profile := &newUser.Profiles[0]
 
 	// Insert user
 	err = h.userRepo.Create(ctx, &newUser)
@@ -123,20 +133,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Generate tokens
-	tokenPair, err := h.tokenService.GenerateTokenPair(userID)
+	tokenPair, err := h.tokenService.GenerateTokenPair(userID, profile.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
 	}
 
 	// Build response
-	resp := buildUserResponse(newUser, tokenPair)
+	resp := buildUserResponse(newUser, profile, tokenPair)
 	c.JSON(http.StatusCreated, resp)
 }
 
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user with email and password
+// @Description  Authenticate user with email and password. The tokens are for profile_id, or the default profile without it. Locked profiles also take the parental PIN; without it the response lists the profiles to pick another one.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
@@ -144,6 +154,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success      200 {object} models.UserResponse "Successfully logged in"
 // @Failure      400 {object} ErrorResponse "Invalid request body"
 // @Failure      401 {object} ErrorResponse "Invalid credentials"
+// @Failure      403 {object} ErrorResponse "Profile is locked or incorrect PIN"
+// @Failure      404 {object} ErrorResponse "Profile not found"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -172,15 +185,38 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Pick the profile
+	var profileID bson.ObjectID
+	if req.ProfileID != "" {
+		profileID, _ = bson.ObjectIDFromHex(req.ProfileID)
+	}
+	profile := user.Profile(profileID)
+	if profile == nil {
+		utils.HandleError(c, repositories.ErrProfileNotFound)
+		return
+	}
+	if profile.Locked && user.ParentalControls != nil {
+		if req.PIN == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":    "Profile is locked, send the parental PIN or pick another profile",
+				"profiles": user.Profiles,
+			})
+			return
+		}
+		if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+			return
+		}
+	}
+
 	// Generate tokens
-	tokenPair, err := h.tokenService.GenerateTokenPair(user.UserID)
+	tokenPair, err := h.tokenService.GenerateTokenPair(user.UserID, profile.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
 	}
 
 	// Build response
-	resp := buildUserResponse(*user, tokenPair)
+	resp := buildUserResponse(*user, profile, tokenPair)
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -278,7 +314,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 // UpdateFavoriteGenres godoc
 // @Summary      Update user's favorite genres
-// @Description  Update authenticated user's favorite genres
+// @Description  Update the favorite genres of the active profile
 // @Tags         Authentication
 // @Security     BearerAuth
 // @Accept       json
@@ -319,8 +355,9 @@ func (h *AuthHandler) UpdateFavoriteGenres(c *gin.Context) {
 		}
 	}
 
-	// Update user's favorite genres
-	err := h.userRepo.UpdateFavoriteGenres(ctx, userID, req.FavouriteGenres)
+	// Update the active profile's favorite genres
+	profileID, _ := middleware.GetProfileID(c)
+	err := h.userRepo.UpdateFavoriteGenres(ctx, userID, profileID, req.FavouriteGenres)
 	if err != nil {
 		utils.HandleError(c, err)
 		return
@@ -356,8 +393,8 @@ func verifyPassword(hashedPassword, password string) bool {
 	return err == nil
 }
 
-// buildUserResponse constructs a UserResponse from User and TokenPair
-func buildUserResponse(user models.User, tokens *models.TokenPair) models.UserResponse {
+// buildUserResponse constructs a UserResponse from User, the profile signed in as and TokenPair
+func buildUserResponse(user models.User, profile *models.Profile, tokens *models.TokenPair) models.UserResponse {
 	return models.UserResponse{
 		UserID:          user.UserID,
 		FirstName:       user.FirstName,
@@ -366,6 +403,8 @@ func buildUserResponse(user models.User, tokens *models.TokenPair) models.UserRe
 		Role:            user.Role,
 		Token:           tokens.AccessToken,
 		RefreshToken:    tokens.RefreshToken,
-		FavouriteGenres: user.FavouriteGenres,
+		ProfileID:       profile.ID.Hex(),
+		FavouriteGenres: profile.FavouriteGenres,
+		Profiles:        user.Profiles,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,321 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CollectionHandler handles admin-curated movie collections
+type CollectionHandler struct {
+	tokenService   *authservice.TokenService
+	collectionRepo repositories.CollectionRepository
+	movieRepo      repositories.MovieRepository
+	rankingRepo    repositories.RankingRepository
+}
+
+// NewCollectionHandler creates a new collection handler with dependencies injected
+func NewCollectionHandler(ts *authservice.TokenService, collectionRepo repositories.CollectionRepository, movieRepo repositories.MovieRepository, rankingRepo repositories.RankingRepository) *CollectionHandler {
+	return &CollectionHandler{
+		tokenService:   ts,
+		collectionRepo: collectionRepo,
+		movieRepo:      movieRepo,
+		rankingRepo:    rankingRepo,
+	}
+}
+
+// GetAll godoc
+// @Summary      List collections
+// @Description  Retrieve every curated collection in home page order
+// @Tags         Collections
+// @Produce      json
+// @Success      200 {array} models.MovieCollection "Collections"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections [get]
+func (h *CollectionHandler) GetAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collections, err := h.collectionRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// GetByID godoc
+// @Summary      Get collection
+// @Description  Retrieve a single curated collection
+// @Tags         Collections
+// @Produce      json
+// @Param        id path string true "Collection ID"
+// @Success      200 {object} models.MovieCollection "Collection details"
+// @Failure      404 {object} ErrorResponse "Collection not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections/{id} [get]
+func (h *CollectionHandler) GetByID(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection, err := h.collectionRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// GetMovies godoc
+// @Summary      Get collection movies
+// @Description  Retrieve the movies of a collection: a manual collection in its curated order, a query collection as its saved query returns them
+// @Tags         Collections
+// @Produce      json
+// @Param        id path string true "Collection ID"
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} MovieListResponse "Movies with pagination info"
+// @Failure      404 {object} ErrorResponse "Collection not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections/{id}/movies [get]
+func (h *CollectionHandler) GetMovies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	collection, err := h.collectionRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	movies, total, err := collectionMovies(ctx, h.movieRepo, h.rankingRepo, collection, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       movies,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// Create godoc
+// @Summary      Create collection
+// @Description  Create a curated collection from a list of movie IDs or a saved GET /movies query (Admin only)
+// @Tags         Collections
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        collection body models.MovieCollectionRequest true "Collection data"
+// @Success      201 {object} models.MovieCollection "Collection created"
+// @Failure      400 {object} ErrorResponse "Invalid request body, unknown movie or invalid query"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections [post]
+func (h *CollectionHandler) Create(c *gin.Context) {
+	var req models.MovieCollectionRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	collection := req.ToCollection()
+	if err := h.validate(ctx, &collection); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	collection.ID = bson.NewObjectID()
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+
+	if err := h.collectionRepo.Create(ctx, &collection); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// Update godoc
+// @Summary      Update collection
+// @Description  Replace a curated collection (Admin only)
+// @Tags         Collections
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Collection ID"
+// @Param        collection body models.MovieCollectionRequest true "Collection data"
+// @Success      200 {object} models.MovieCollection "Collection updated"
+// @Failure      400 {object} ErrorResponse "Invalid request body, unknown movie or invalid query"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Collection not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections/{id} [put]
+func (h *CollectionHandler) Update(c *gin.Context) {
+	var req models.MovieCollectionRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	existing, err := h.collectionRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	collection := req.ToCollection()
+	if err := h.validate(ctx, &collection); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.collectionRepo.Replace(ctx, existing.ID, &collection); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	updated, err := h.collectionRepo.FindByID(ctx, existing.ID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary      Delete collection
+// @Description  Remove a curated collection; its movies are not affected (Admin only)
+// @Tags         Collections
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Collection ID"
+// @Success      200 {object} MessageResponse "Collection deleted"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Collection not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /collections/{id} [delete]
+func (h *CollectionHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.collectionRepo.Delete(ctx, c.Param("id")); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// validate checks that a manual collection lists existing movies once each and
+// that a query collection holds a query GET /movies would accept
+func (h *CollectionHandler) validate(ctx context.Context, collection *models.MovieCollection) error {
+	if collection.Kind == models.CollectionKindQuery {
+		query, err := url.ParseQuery(collection.Query)
+		if err != nil {
+			return utils.NewAppError(http.StatusBadRequest, "Invalid query", err.Error())
+		}
+		if _, err := movieQueryFilter(ctx, h.rankingRepo, query); err != nil {
+			return err
+		}
+		_, err = movieQuerySort(query)
+		return err
+	}
+
+	seen := make(map[bson.ObjectID]bool, len(collection.MovieIDs))
+	for _, id := range collection.MovieIDs {
+		if seen[id] {
+			return utils.NewAppError(http.StatusBadRequest, "Movie listed more than once", id.Hex())
+		}
+		seen[id] = true
+	}
+
+	count, err := h.movieRepo.Count(ctx, bson.M{"_id": bson.M{"$in": collection.MovieIDs}})
+	if err != nil {
+		return err
+	}
+	if count != int64(len(collection.MovieIDs)) {
+		return utils.NewAppError(http.StatusBadRequest, "Unknown movie in collection")
+	}
+
+	return nil
+}
+
+// collectionMovies returns a page of a collection's movies and their total.
+// Manual collections keep their curated order and leave out trashed movies.
+func collectionMovies(ctx context.Context, movieRepo repositories.MovieRepository, rankingRepo repositories.RankingRepository, collection *models.MovieCollection, limit, skip int64) ([]models.Movie, int64, error) {
+	if collection.Kind == models.CollectionKindQuery {
+		query, err := url.ParseQuery(collection.Query)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter, err := movieQueryFilter(ctx, rankingRepo, query)
+		if err != nil {
+			return nil, 0, err
+		}
+		sort, err := movieQuerySort(query)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		total, err := movieRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		movies, err := movieRepo.FindAll(ctx, filter, options.Find().SetLimit(limit).SetSkip(skip).SetSort(sort))
+		if err != nil {
+			return nil, 0, err
+		}
+		return movies, total, nil
+	}
+
+	total := int64(len(collection.MovieIDs))
+	start := min(skip, total)
+	ids := collection.MovieIDs[start:min(start+limit, total)]
+
+	movies, err := movieRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, 0, err
+	}
+	moviesByID := make(map[bson.ObjectID]models.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	ordered := []models.Movie{}
+	for _, id := range ids {
+		if movie, ok := moviesByID[id]; ok {
+			ordered = append(ordered, movie)
+		}
+	}
+
+	return ordered, total, nil
+}
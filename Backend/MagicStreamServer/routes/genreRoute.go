@@ -2,12 +2,13 @@ package routes
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
-	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/database"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
@@ -18,44 +19,74 @@ import (
 type GenreHandler struct {
 	tokenService *authservice.TokenService
 	genreRepo    repositories.GenreRepository
+	movieRepo    repositories.MovieRepository
+	locales      *utils.Locales
 }
 
 // NewGenreHandler creates a new genre handler with dependencies injected
-func NewGenreHandler(ts *authservice.TokenService, genreRepo repositories.GenreRepository) *GenreHandler {
+func NewGenreHandler(ts *authservice.TokenService, genreRepo repositories.GenreRepository, movieRepo repositories.MovieRepository, locales *utils.Locales) *GenreHandler {
 	return &GenreHandler{
 		tokenService: ts,
 		genreRepo:    genreRepo,
+		movieRepo:    movieRepo,
+		locales:      locales,
 	}
 }
 
 // GetAllGenres godoc
 // @Summary      Get all available genres
-// @Description  Retrieve list of all movie genres for user selection
+// @Description  Retrieve list of all movie genres for user selection, with names in the negotiated locale
 // @Tags         Genres
 // @Produce      json
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Param        If-Modified-Since header string false "Last-Modified of a cached copy"
 // @Success      200 {array} models.Genre "List of genres"
+// @Success      304 "Cached copy is still current"
+// @Header       200 {string} ETag "Genre list version tag"
+// @Header       200 {string} Last-Modified "Time of the latest genre change"
+// @Header       200 {string} Content-Language "Locale of the genre names"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /genres [get]
 func (h *GenreHandler) GetAllGenres(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	chain := middleware.GetLocaleChain(c)
+	utils.ContentLanguage(c, chain)
+
+	state, err := h.genreRepo.State(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if utils.NotModified(c, middleware.GetCacheControl(c), utils.CollectionETag(state, c.Request.URL.RequestURI()+utils.LocaleParams(chain)), state.LastModified) {
+		return
+	}
+
 	genres, err := h.genreRepo.FindAll(ctx)
 	if err != nil {
 		utils.HandleError(c, err)
 		return
 	}
+	for i := range genres {
+		genres[i].Localize(chain)
+	}
 
 	c.JSON(http.StatusOK, genres)
 }
 
 // GetGenreByID godoc
 // @Summary      Get genre by ID
-// @Description  Retrieve a single genre by its ID
+// @Description  Retrieve a single genre by its ID, named in the negotiated locale
 // @Tags         Genres
 // @Produce      json
 // @Param        id path int true "Genre ID"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
 // @Success      200 {object} models.Genre "Genre details"
+// @Header       200 {string} Content-Language "Locale of the genre name"
 // @Failure      404 {object} ErrorResponse "Genre not found"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /genres/{id} [get]
@@ -76,6 +107,67 @@ func (h *GenreHandler) GetGenreByID(c *gin.Context) {
 		return
 	}
 
+	chain := middleware.GetLocaleChain(c)
+	genre.Localize(chain)
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, genre)
+}
+
+// UpdateTranslations godoc
+// @Summary      Set genre translations (Admin only)
+// @Description  Replace the translated names of a genre, keyed by supported locale. The default locale name stays in genre_name. Movies in the genre pick up the new names.
+// @Tags         Genres
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Genre ID"
+// @Param        translations body models.GenreTranslationsRequest true "Genre names keyed by locale"
+// @Success      200 {object} models.Genre "Genre with its translations"
+// @Failure      400 {object} ErrorResponse "Invalid or unsupported locale"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Genre not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /genres/{id}/translations [put]
+func (h *GenreHandler) UpdateTranslations(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	genreID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid genre ID"})
+		return
+	}
+
+	var req models.GenreTranslationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, utils.NewAppError(http.StatusBadRequest, "Invalid request data", err.Error()))
+		return
+	}
+
+	translations := make(map[string]string, len(req.Translations))
+	for locale, name := range req.Translations {
+		key, err := h.locales.TranslationKey(locale)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		translations[key] = name
+	}
+
+	genre, err := h.genreRepo.SetTranslations(ctx, genreID, translations)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Movies embed their genres, so their copies are refreshed too
+	if err := h.movieRepo.SyncGenre(ctx, genre); err != nil {
+		log.Printf("failed to sync genre %d onto movies: %v", genre.GenreID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Genre saved but movies could not be updated, retry the request"})
+		return
+	}
+
 	c.JSON(http.StatusOK, genre)
 }
 
@@ -119,29 +211,35 @@ func (h *GenreHandler) SeedGenres(c *gin.Context) {
 	})
 }
 
-// ValidateGenres checks if provided genre IDs exist in database
-func ValidateGenres(ctx context.Context, genres []models.Genre) (bool, error) {
-	if len(genres) == 0 {
-		return false, nil
+// resolveGenres replaces a movie's genres with their catalog copies, names and
+// translations included, keeping the given order and dropping repeats
+func resolveGenres(ctx context.Context, genreRepo repositories.GenreRepository, genres []models.Genre) ([]models.Genre, error) {
+	ids := make([]int, 0, len(genres))
+	for _, genre := range genres {
+		ids = append(ids, genre.GenreID)
 	}
 
-	// Extract genre IDs
-	genreIDs := make([]int, len(genres))
-	for i, genre := range genres {
-		genreIDs[i] = genre.GenreID
+	catalog, err := genreRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Genre, len(catalog))
+	for _, genre := range catalog {
+		byID[genre.GenreID] = genre
 	}
 
-	// Check if all genres exist
-	_, err := GetGenresByIDs(ctx, genreIDs)
-	if err != nil {
-		return false, err
+	resolved := make([]models.Genre, 0, len(genres))
+	seen := make(map[int]bool, len(genres))
+	for _, genre := range genres {
+		found, ok := byID[genre.GenreID]
+		if !ok {
+			return nil, utils.NewAppError(http.StatusBadRequest, "One or more genres are invalid", strconv.Itoa(genre.GenreID))
+		}
+		if !seen[genre.GenreID] {
+			seen[genre.GenreID] = true
+			resolved = append(resolved, found)
+		}
 	}
 
-	return true, nil
+	return resolved, nil
 }
-
-// GetGenresByIDs retrieves full genre data by IDs
-func GetGenresByIDs(ctx context.Context, genreIDs []int) ([]models.Genre, error) {
-	genreRepo := repositories.NewGenreRepository(database.OpenCollection("genres"))
-	return genreRepo.FindByIDs(ctx, genreIDs)
-}
\ No newline at end of file
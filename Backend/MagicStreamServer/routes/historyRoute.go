@@ -0,0 +1,181 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HistoryHandler handles watch history and resume-playback progress
+type HistoryHandler struct {
+	tokenService *authservice.TokenService
+	cfg          *config.Config
+	historyRepo  repositories.WatchHistoryRepository
+	movieRepo    repositories.MovieRepository
+}
+
+// NewHistoryHandler creates a new history handler with dependencies injected
+func NewHistoryHandler(ts *authservice.TokenService, cfg *config.Config, historyRepo repositories.WatchHistoryRepository, movieRepo repositories.MovieRepository) *HistoryHandler {
+	return &HistoryHandler{
+		tokenService: ts,
+		cfg:          cfg,
+		historyRepo:  historyRepo,
+		movieRepo:    movieRepo,
+	}
+}
+
+// WatchProgressResponse for Swagger documentation
+type WatchProgressResponse struct {
+	Saved     bool `json:"saved" example:"true"`
+	Completed bool `json:"completed" example:"false"`
+}
+
+// WatchHistoryResponse for Swagger documentation
+type WatchHistoryResponse struct {
+	Data       []models.WatchHistoryItem `json:"data"`
+	Pagination PaginationInfo            `json:"pagination"`
+}
+
+// UpdateProgress godoc
+// @Summary      Report playback progress
+// @Description  Record where the caller is in a movie. Reports arriving more often than the configured interval on the same device are coalesced (saved is false). Passing the completion threshold marks the movie watched.
+// @Tags         History
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        progress body models.WatchProgressRequest true "Playback position"
+// @Success      200 {object} WatchProgressResponse "Progress recorded or coalesced"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/progress [post]
+func (h *HistoryHandler) UpdateProgress(c *gin.Context) {
+	var req models.WatchProgressRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	movieID, err := bson.ObjectIDFromHex(req.MovieID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.movieRepo.FindByID(ctx, movieID.Hex()); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	profileID, _ := middleware.GetProfileID(c)
+	completed := float64(req.PositionSeconds) >= h.completionThreshold()*float64(req.DurationSeconds)
+	interval := time.Duration(h.cfg.WatchProgressIntervalSec) * time.Second
+
+	saved, err := h.historyRepo.SaveProgress(ctx, userID, profileID, movieID, &req, completed, interval)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, WatchProgressResponse{Saved: saved, Completed: completed})
+}
+
+// GetHistory godoc
+// @Summary      Get my watch history
+// @Description  Retrieve every movie the caller started or finished, most recently watched first
+// @Tags         History
+// @Security     BearerAuth
+// @Produce      json
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} WatchHistoryResponse "Watch history with pagination info"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/history [get]
+func (h *HistoryHandler) GetHistory(c *gin.Context) {
+	h.list(c, false)
+}
+
+// GetContinueWatching godoc
+// @Summary      Continue watching
+// @Description  Retrieve the movies the caller started but has not finished, most recently watched first, with the position to resume from
+// @Tags         History
+// @Security     BearerAuth
+// @Produce      json
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} WatchHistoryResponse "In-progress movies with pagination info"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/continue-watching [get]
+func (h *HistoryHandler) GetContinueWatching(c *gin.Context) {
+	h.list(c, true)
+}
+
+// list writes a page of the caller's history with the movies filled in.
+// Movies in the trash are left out of the page.
+func (h *HistoryHandler) list(c *gin.Context, inProgressOnly bool) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	profileID, _ := middleware.GetProfileID(c)
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	entries, total, err := h.historyRepo.FindByProfile(ctx, profileID, inProgressOnly, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	ids := make([]bson.ObjectID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MovieID
+	}
+
+	movies, err := h.movieRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
+	moviesByID := make(map[bson.ObjectID]models.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	items := []models.WatchHistoryItem{}
+	for _, entry := range entries {
+		if movie, ok := moviesByID[entry.MovieID]; ok {
+			items = append(items, models.WatchHistoryItem{WatchHistory: entry, Movie: movie})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       items,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// completionThreshold is the share of a movie that counts as watching it,
+// falling back to the whole movie when misconfigured
+func (h *HistoryHandler) completionThreshold() float64 {
+	if h.cfg.WatchCompletionThreshold <= 0 || h.cfg.WatchCompletionThreshold > 1 {
+		return 1
+	}
+	return h.cfg.WatchCompletionThreshold
+}
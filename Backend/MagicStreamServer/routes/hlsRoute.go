@@ -0,0 +1,322 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/storage"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// HLSHandler serves the HLS playlists and segments of movies to signed-in
+// users, limited to the movies and qualities their plan includes
+type HLSHandler struct {
+	tokenService *authservice.TokenService
+	cfg          *config.Config
+	movieRepo    repositories.MovieRepository
+	playlistRepo repositories.HLSPlaylistRepository
+	blobStore    storage.BlobStore
+}
+
+// NewHLSHandler creates a new HLS handler with dependencies injected
+func NewHLSHandler(ts *authservice.TokenService, cfg *config.Config, movieRepo repositories.MovieRepository, playlistRepo repositories.HLSPlaylistRepository, blobStore storage.BlobStore) *HLSHandler {
+	return &HLSHandler{
+		tokenService: ts,
+		cfg:          cfg,
+		movieRepo:    movieRepo,
+		playlistRepo: playlistRepo,
+		blobStore:    blobStore,
+	}
+}
+
+// Master godoc
+// @Summary      HLS master playlist
+// @Description  Adaptive bitrate master playlist listing the renditions of the movie the caller's plan allows, lowest bandwidth first, and its subtitle tracks. Point the player at this URL.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      application/vnd.apple.mpegurl
+// @Param        movie_id path string true "Movie ID"
+// @Success      200 {string} string "Master playlist"
+// @Success      304 "Not modified"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie, or all of its renditions, not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie not found or has no renditions"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /hls/{movie_id}/master.m3u8 [get]
+func (h *HLSHandler) Master(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	movie, ok := h.findMovie(ctx, c)
+	if !ok {
+		return
+	}
+	if len(movie.Renditions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no HLS renditions"})
+		return
+	}
+
+	maxQuality := h.maxQuality(c)
+	renditions := make(map[string]models.HLSRendition, len(movie.Renditions))
+	for name, rendition := range movie.Renditions {
+		if withinQuality(rendition.Height, maxQuality) {
+			renditions[name] = rendition
+		}
+	}
+	if len(renditions) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your plan does not include the qualities this movie is available in"})
+		return
+	}
+
+	// Renditions are added and replaced over time, so players revalidate. The
+	// cap is part of the ETag, since an upgrade lists more renditions.
+	etag := utils.MovieETag(movie.Version)
+	if maxQuality > 0 {
+		etag = fmt.Sprintf(`"v%d-q%d"`, movie.Version, maxQuality)
+	}
+	if utils.NotModified(c, "private, no-cache", etag, movie.UpdatedAt) {
+		return
+	}
+
+	c.Data(http.StatusOK, utils.HLSPlaylistContentType, []byte(utils.MasterPlaylistM3U8(renditions, movie.Subtitles)))
+}
+
+// Media godoc
+// @Summary      HLS media playlist
+// @Description  VOD media playlist of one rendition. Segment URIs are relative to the playlist.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      application/vnd.apple.mpegurl
+// @Param        movie_id path string true "Movie ID"
+// @Param        rendition path string true "Rendition name"
+// @Success      200 {string} string "Media playlist"
+// @Success      304 "Not modified"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie or rendition quality not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie or rendition not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /hls/{movie_id}/{rendition}/index.m3u8 [get]
+func (h *HLSHandler) Media(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	movie, ok := h.findMovie(ctx, c)
+	if !ok {
+		return
+	}
+	rendition, ok := movie.Renditions[c.Param("rendition")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rendition not found"})
+		return
+	}
+	if !withinQuality(rendition.Height, h.maxQuality(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your plan does not include this quality"})
+		return
+	}
+
+	playlist, err := h.playlistRepo.FindByID(ctx, rendition.PlaylistID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPlaylistNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rendition not found"})
+			return
+		}
+		utils.HandleError(c, err)
+		return
+	}
+
+	// The URL stays the same when a rendition is replaced, the playlist ID does not
+	if utils.NotModified(c, "private, no-cache", `"`+playlist.ID.Hex()+`"`, playlist.CreatedAt) {
+		return
+	}
+
+	c.Data(http.StatusOK, utils.HLSPlaylistContentType, []byte(utils.MediaPlaylistM3U8(playlist, playlist.ID.Hex()+"/")))
+}
+
+// Segment godoc
+// @Summary      HLS segment
+// @Description  One media or init segment of a rendition. Segment URLs include the upload they belong to, so they never change and are cached as immutable. Signed requests locate the blob from the URL alone, since the movie was checked when playback started; bearer requests are checked against the movie like the playlists. Supports Range requests.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      video/mp2t
+// @Produce      video/iso.segment
+// @Param        movie_id path string true "Movie ID"
+// @Param        rendition path string true "Rendition name"
+// @Param        playlist_id path string true "Playlist ID from the media playlist"
+// @Param        segment path string true "Segment file name"
+// @Success      200 {file} file "Segment"
+// @Success      206 {file} file "Requested byte range"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie or rendition quality not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie, rendition or segment not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /hls/{movie_id}/{rendition}/{playlist_id}/{segment} [get]
+func (h *HLSHandler) Segment(c *gin.Context) {
+	movieID, err := bson.ObjectIDFromHex(c.Param("movie_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	// Replaced uploads are deleted in the background, so a stale playlist ID
+	// finds no blob once that is done
+	name := c.Param("rendition")
+	playlistID, err := bson.ObjectIDFromHex(c.Param("playlist_id"))
+	if err != nil || !videoAssetName.MatchString(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rendition not found"})
+		return
+	}
+
+	if _, signed := middleware.GetPlaybackClaims(c); !signed && !h.checkSegmentAccess(c, name) {
+		return
+	}
+
+	segment := c.Param("segment")
+	contentType, ok := utils.HLSSegmentContentType(segment)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Segment not found"})
+		return
+	}
+
+	blob, info, err := h.blobStore.Open(c.Request.Context(), hlsKeyPrefix(movieID.Hex(), name, playlistID)+"/"+segment)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Segment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open segment"})
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+
+	http.ServeContent(streamWriter{c.Writer}, c.Request, "", info.ModTime, blob)
+}
+
+// checkSegmentAccess applies the checks of the media playlist to a bearer
+// segment request: the movie is visible to the caller and in their plan, and
+// the rendition exists within their quality cap. It writes the error response
+// itself when it returns false.
+func (h *HLSHandler) checkSegmentAccess(c *gin.Context, name string) bool {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	movie, ok := h.findMovie(ctx, c)
+	if !ok {
+		return false
+	}
+	rendition, ok := movie.Renditions[name]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rendition not found"})
+		return false
+	}
+	if !withinQuality(rendition.Height, h.maxQuality(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your plan does not include this quality"})
+		return false
+	}
+	return true
+}
+
+// Subtitle godoc
+// @Summary      HLS subtitle playlist
+// @Description  Media playlist of one subtitle track, referenced from the master playlist. It lists the track's WebVTT file as a single segment spanning the movie.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      application/vnd.apple.mpegurl
+// @Param        movie_id path string true "Movie ID"
+// @Param        lang path string true "Track language"
+// @Param        playlist path string true "Track kind followed by .m3u8 (subtitles.m3u8 or captions.m3u8)"
+// @Success      200 {string} string "Subtitle playlist"
+// @Success      304 "Not modified"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie, renditions or subtitle track not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /hls/{movie_id}/subtitles/{lang}/{playlist} [get]
+func (h *HLSHandler) Subtitle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	movie, ok := h.findMovie(ctx, c)
+	if !ok {
+		return
+	}
+
+	kind, _ := strings.CutSuffix(c.Param("playlist"), ".m3u8")
+	var track *models.SubtitleTrack
+	for i, existing := range movie.Subtitles {
+		if existing.Lang == c.Param("lang") && existing.Kind == kind {
+			track = &movie.Subtitles[i]
+			break
+		}
+	}
+	if track == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtitle track not found"})
+		return
+	}
+
+	// The track spans the longest rendition
+	duration := 0.0
+	for _, rendition := range movie.Renditions {
+		duration = max(duration, rendition.Duration)
+	}
+	if duration == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie has no HLS renditions"})
+		return
+	}
+
+	if utils.NotModified(c, "private, no-cache", utils.MovieETag(movie.Version), movie.UpdatedAt) {
+		return
+	}
+
+	c.Data(http.StatusOK, utils.HLSPlaylistContentType, []byte(utils.SubtitlePlaylistM3U8(track.URL, duration)))
+}
+
+// findMovie loads the movie in the path, writing the error response itself
+// when ok is false. Signed requests were checked against the plan when
+// playback started; bearer requests are checked here.
+func (h *HLSHandler) findMovie(ctx context.Context, c *gin.Context) (*models.Movie, bool) {
+	objectID, err := bson.ObjectIDFromHex(c.Param("movie_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return nil, false
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return nil, false
+	}
+
+	if _, signed := middleware.GetPlaybackClaims(c); !signed && !checkEntitlement(c, middleware.GetPlan(c), movie) {
+		return nil, false
+	}
+
+	return movie, true
+}
+
+// maxQuality returns the highest rendition height the caller may watch, 0
+// meaning no cap. Signed requests keep the cap of the plan playback started
+// with until the URL expires.
+func (h *HLSHandler) maxQuality(c *gin.Context) int {
+	if claims, ok := middleware.GetPlaybackClaims(c); ok {
+		return claims.MaxQuality
+	}
+	return planFeatures(h.cfg, middleware.GetPlan(c)).MaxQuality
+}
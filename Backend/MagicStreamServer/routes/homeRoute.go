@@ -0,0 +1,357 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Home rail types; a rail ID is its type, followed by the collection or genre ID for those rails
+const (
+	RailContinueWatching = "continue-watching"
+	RailRecommendations  = "recommendations"
+	RailCollection       = "collection"
+	RailGenre            = "genre"
+)
+
+// HomeHandler assembles the home page rails
+type HomeHandler struct {
+	tokenService   *authservice.TokenService
+	movieRepo      repositories.MovieRepository
+	genreRepo      repositories.GenreRepository
+	collectionRepo repositories.CollectionRepository
+	historyRepo    repositories.WatchHistoryRepository
+	rankingRepo    repositories.RankingRepository
+}
+
+// NewHomeHandler creates a new home handler with dependencies injected
+func NewHomeHandler(ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, collectionRepo repositories.CollectionRepository, historyRepo repositories.WatchHistoryRepository, rankingRepo repositories.RankingRepository) *HomeHandler {
+	return &HomeHandler{
+		tokenService:   ts,
+		movieRepo:      movieRepo,
+		genreRepo:      genreRepo,
+		collectionRepo: collectionRepo,
+		historyRepo:    historyRepo,
+		rankingRepo:    rankingRepo,
+	}
+}
+
+// HomeRail is one row of the home page with the first page of its movies.
+// Further pages come from GET /home/rails/{id}.
+type HomeRail struct {
+	ID         string         `json:"id" example:"collection-665f1c2e8a1b2c3d4e5f6a7b"`
+	Type       string         `json:"type" example:"collection"`
+	Title      string         `json:"title" example:"Staff Picks"`
+	Items      []HomeRailItem `json:"items"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// HomeRailItem is a movie on a rail, with the resume position on continue watching
+type HomeRailItem struct {
+	Movie    models.Movie         `json:"movie"`
+	Progress *models.WatchHistory `json:"progress,omitempty"`
+}
+
+// HomeResponse for Swagger documentation
+type HomeResponse struct {
+	Rails []HomeRail `json:"rails"`
+}
+
+// GetHome godoc
+// @Summary      Get home page
+// @Description  Assemble the home page rails: continue watching and recommendations for signed-in callers, then the curated collections, then one row per genre (favourite genres first). Empty rails are left out.
+// @Tags         Home
+// @Produce      json
+// @Param        limit query int false "Movies per rail (default 10, max 50)"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Success      200 {object} HomeResponse "Home page rails"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /home [get]
+func (h *HomeHandler) GetHome(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 10*time.Second)
+	defer cancel()
+
+	limit := utils.ParsePaginationParams(c.Query("limit"), "", 10, 50).Limit
+	profile, signedIn := middleware.GetProfile(c)
+	chain := middleware.GetLocaleChain(c)
+
+	var rails []*HomeRail
+	favourites := map[int]bool{}
+
+	if signedIn {
+		utils.Personalized(c)
+
+		rail, err := h.continueWatchingRail(ctx, profile.ID, limit, 0)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		rails = append(rails, rail)
+
+		if rail, err = h.recommendationsRail(ctx, profile, limit, 0); err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		rails = append(rails, rail)
+
+		for _, genre := range profile.FavouriteGenres {
+			favourites[genre.GenreID] = true
+		}
+	} else {
+		c.Writer.Header().Add("Vary", "Authorization")
+	}
+
+	collections, err := h.collectionRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	for i := range collections {
+		rail, err := h.collectionRail(ctx, &collections[i], limit, 0)
+		if err != nil {
+			// A saved query can go stale, e.g. when its ranking is deleted; skip that rail
+			var appErr *utils.AppError
+			if errors.As(err, &appErr) {
+				log.Printf("skipping home rail of collection %s: %v", collections[i].ID.Hex(), err)
+				continue
+			}
+			utils.HandleError(c, err)
+			return
+		}
+		rails = append(rails, rail)
+	}
+
+	genres, err := h.genreRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	genreRails := make([]*HomeRail, 0, len(genres))
+	for i := range genres {
+		genres[i].Localize(chain)
+		rail, err := h.genreRail(ctx, &genres[i], limit, 0)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		if favourites[genres[i].GenreID] {
+			genreRails = append([]*HomeRail{rail}, genreRails...)
+		} else {
+			genreRails = append(genreRails, rail)
+		}
+	}
+	rails = append(rails, genreRails...)
+
+	response := HomeResponse{Rails: []HomeRail{}}
+	for _, rail := range rails {
+		if len(rail.Items) > 0 {
+			rail.localize(chain)
+			response.Rails = append(response.Rails, *rail)
+		}
+	}
+
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRail godoc
+// @Summary      Get home rail page
+// @Description  Retrieve a further page of one home page rail. Continue watching and recommendations require sign-in.
+// @Tags         Home
+// @Produce      json
+// @Param        id path string true "Rail ID, e.g. continue-watching, recommendations, collection-{id} or genre-{id}"
+// @Param        limit query int false "Limit results (default 10, max 50)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Success      200 {object} HomeRail "Rail page"
+// @Failure      401 {object} ErrorResponse "Sign-in required for this rail"
+// @Failure      404 {object} ErrorResponse "Rail not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /home/rails/{id} [get]
+func (h *HomeHandler) GetRail(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 10, 50)
+	profile, signedIn := middleware.GetProfile(c)
+	chain := middleware.GetLocaleChain(c)
+	railID := c.Param("id")
+
+	var rail *HomeRail
+	var err error
+
+	switch railID {
+	case RailContinueWatching, RailRecommendations:
+		if !signedIn {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in to see this rail"})
+			return
+		}
+		utils.Personalized(c)
+
+		if railID == RailContinueWatching {
+			rail, err = h.continueWatchingRail(ctx, profile.ID, pagination.Limit, pagination.Skip)
+			break
+		}
+		rail, err = h.recommendationsRail(ctx, profile, pagination.Limit, pagination.Skip)
+
+	default:
+		railType, ref, _ := strings.Cut(railID, "-")
+		switch railType {
+		case RailCollection:
+			var collection *models.MovieCollection
+			if collection, err = h.collectionRepo.FindByID(ctx, ref); err == nil {
+				rail, err = h.collectionRail(ctx, collection, pagination.Limit, pagination.Skip)
+			}
+		case RailGenre:
+			genreID, convErr := strconv.Atoi(ref)
+			if convErr != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Rail not found"})
+				return
+			}
+			var genre *models.Genre
+			if genre, err = h.genreRepo.FindByID(ctx, genreID); err == nil {
+				genre.Localize(chain)
+				rail, err = h.genreRail(ctx, genre, pagination.Limit, pagination.Skip)
+			}
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rail not found"})
+			return
+		}
+	}
+
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	rail.localize(chain)
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, rail)
+}
+
+func (h *HomeHandler) continueWatchingRail(ctx context.Context, profileID bson.ObjectID, limit, skip int64) (*HomeRail, error) {
+	entries, total, err := h.historyRepo.FindByProfile(ctx, profileID, true, limit, skip)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]bson.ObjectID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MovieID
+	}
+	movies, err := h.movieRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	moviesByID := make(map[bson.ObjectID]models.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	rail := newHomeRail(RailContinueWatching, RailContinueWatching, "Continue Watching", total, limit, skip)
+	for i := range entries {
+		if movie, ok := moviesByID[entries[i].MovieID]; ok {
+			rail.Items = append(rail.Items, HomeRailItem{Movie: movie, Progress: &entries[i]})
+		}
+	}
+	return rail, nil
+}
+
+func (h *HomeHandler) recommendationsRail(ctx context.Context, profile *models.Profile, limit, skip int64) (*HomeRail, error) {
+	rail := newHomeRail(RailRecommendations, RailRecommendations, "Recommended for You", 0, limit, skip)
+	if len(profile.FavouriteGenres) == 0 {
+		return rail, nil
+	}
+
+	genreIDs := make([]int, len(profile.FavouriteGenres))
+	for i, genre := range profile.FavouriteGenres {
+		genreIDs[i] = genre.GenreID
+	}
+
+	total, err := h.movieRepo.Count(ctx, bson.M{"genre.genre_id": bson.M{"$in": genreIDs}})
+	if err != nil {
+		return nil, err
+	}
+	movies, err := h.movieRepo.FindByGenres(ctx, genreIDs, int(limit), int(skip))
+	if err != nil {
+		return nil, err
+	}
+
+	rail.Pagination = newPaginationInfo(total, limit, skip)
+	rail.addMovies(movies)
+	return rail, nil
+}
+
+func (h *HomeHandler) collectionRail(ctx context.Context, collection *models.MovieCollection, limit, skip int64) (*HomeRail, error) {
+	movies, total, err := collectionMovies(ctx, h.movieRepo, h.rankingRepo, collection, limit, skip)
+	if err != nil {
+		return nil, err
+	}
+
+	rail := newHomeRail(RailCollection+"-"+collection.ID.Hex(), RailCollection, collection.Name, total, limit, skip)
+	rail.addMovies(movies)
+	return rail, nil
+}
+
+func (h *HomeHandler) genreRail(ctx context.Context, genre *models.Genre, limit, skip int64) (*HomeRail, error) {
+	total, err := h.movieRepo.Count(ctx, bson.M{"genre.genre_id": genre.GenreID})
+	if err != nil {
+		return nil, err
+	}
+	movies, err := h.movieRepo.FindByGenre(ctx, genre.GenreID, int(limit), int(skip))
+	if err != nil {
+		return nil, err
+	}
+
+	rail := newHomeRail(RailGenre+"-"+strconv.Itoa(genre.GenreID), RailGenre, genre.GenreName, total, limit, skip)
+	rail.addMovies(movies)
+	return rail, nil
+}
+
+func newHomeRail(id, railType, title string, total, limit, skip int64) *HomeRail {
+	return &HomeRail{
+		ID:         id,
+		Type:       railType,
+		Title:      title,
+		Items:      []HomeRailItem{},
+		Pagination: newPaginationInfo(total, limit, skip),
+	}
+}
+
+func (r *HomeRail) addMovies(movies []models.Movie) {
+	for _, movie := range movies {
+		r.Items = append(r.Items, HomeRailItem{Movie: movie})
+	}
+}
+
+// localize rewrites the movies on the rail in the locale chain
+func (r *HomeRail) localize(chain models.LocaleChain) {
+	for i := range r.Items {
+		r.Items[i].Movie.Localize(chain)
+	}
+}
+
+// newPaginationInfo mirrors utils.CalculatePaginationInfo for typed responses
+func newPaginationInfo(total, limit, skip int64) PaginationInfo {
+	return PaginationInfo{
+		Total:       total,
+		Limit:       int(limit),
+		Skip:        int(skip),
+		TotalPages:  (total + limit - 1) / limit,
+		CurrentPage: int(skip/limit) + 1,
+	}
+}
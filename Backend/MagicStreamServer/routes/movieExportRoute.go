@@ -0,0 +1,210 @@
+package routes
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery controls how many rows are buffered before flushing to the client
+const exportFlushEvery = 100
+
+// Export godoc
+// @Summary      Export movie catalog
+// @Description  Stream the catalog as NDJSON or CSV straight from the database cursor (Admin only). Accepts the same filters as GET /movies. CSV output flattens genre into genre_ids/genre_names and ranking into ranking_value/ranking_name.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      plain
+// @Param        format query string false "Output format: ndjson (default) or csv"
+// @Param        fields query string false "Comma separated fields to include (default all)"
+// @Param        genre query string false "Filter by genre name"
+// @Param        ranking query int false "Only movies ranked at least as well as this ranking value"
+// @Param        year_from query int false "Filter by minimum release year"
+// @Param        year_to query int false "Filter by maximum release year"
+// @Param        min_runtime query int false "Filter by minimum runtime in minutes"
+// @Param        max_runtime query int false "Filter by maximum runtime in minutes"
+// @Param        language query string false "Filter by original or spoken language (e.g. en)"
+// @Param        subtitle query string false "Filter by subtitle language, declared or with an uploaded track (e.g. id)"
+// @Param        country query string false "Filter by country code (e.g. US)"
+// @Param        certification query string false "Filter by age certification (G, PG, PG-13, R, NC-17, NR)"
+// @Param        director query string false "Filter by director name"
+// @Param        cast query string false "Filter by cast member name"
+// @Param        min_rating query number false "Filter by minimum average user rating (1-10)"
+// @Param        min_ratings query int false "Filter by minimum number of user ratings"
+// @Success      200 {string} string "Exported movies"
+// @Failure      400 {object} ErrorResponse "Invalid format, field or filter"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Router       /admin/movies/export [get]
+func (h *MovieHandler) Export(c *gin.Context) {
+	filter, err := h.movieListFilter(c.Request.Context(), c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	var fields []string
+	for _, field := range strings.Split(c.Query("fields"), ",") {
+		if field = utils.SanitizeString(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "ndjson"))
+	var write func(movie *models.Movie) error
+	var flush func() error
+
+	buffered := bufio.NewWriter(c.Writer)
+
+	switch format {
+	case "ndjson":
+		keep, err := movieJSONFields(fields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		encoder := json.NewEncoder(buffered)
+		write = func(movie *models.Movie) error {
+			if keep == nil {
+				return encoder.Encode(movie)
+			}
+			row, err := projectMovieJSON(movie, keep)
+			if err != nil {
+				return err
+			}
+			return encoder.Encode(row)
+		}
+		flush = buffered.Flush
+		c.Header("Content-Type", "application/x-ndjson")
+	case "csv":
+		columns, err := utils.ExpandCSVFields(fields)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		writer := csv.NewWriter(buffered)
+		write = func(movie *models.Movie) error {
+			return writer.Write(utils.MovieCSVRecord(movie, columns))
+		}
+		flush = func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		if err := writer.Write(columns); err != nil {
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format. Use ndjson or csv"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="movies-%s.%s"`, time.Now().Format("20060102"), format))
+	c.Status(http.StatusOK)
+
+	// The export runs as long as the client keeps reading
+	ctx := repositories.WithoutMaturityLimit(c.Request.Context())
+
+	rows := 0
+	err = h.movieRepo.ForEach(ctx, filter, func(movie *models.Movie) error {
+		if err := write(movie); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil && ctx.Err() != context.Canceled {
+		// Headers are already sent, so the failure can only be logged
+		log.Printf("movie export aborted after %d rows: %v", rows, err)
+	}
+	c.Writer.Flush()
+}
+
+// movieJSONFields validates requested NDJSON fields against the movie JSON keys
+func movieJSONFields(fields []string) (map[string]bool, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	known := movieJSONKeys()
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if _, ok := known[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		keep[field] = true
+	}
+
+	return keep, nil
+}
+
+// movieJSONKeys lists the JSON keys of a movie from the struct tags, so keys
+// left out of an empty movie by omitempty are known too
+func movieJSONKeys() map[string]bool {
+	movieType := reflect.TypeOf(models.Movie{})
+	keys := make(map[string]bool, movieType.NumField())
+	for i := 0; i < movieType.NumField(); i++ {
+		field := movieType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		keys[name] = true
+	}
+	return keys
+}
+
+// projectMovieJSON returns the movie's JSON object limited to the kept keys (all keys when keep is nil)
+func projectMovieJSON(movie *models.Movie, keep map[string]bool) (map[string]interface{}, error) {
+	raw, err := json.Marshal(movie)
+	if err != nil {
+		return nil, err
+	}
+
+	var row map[string]interface{}
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, err
+	}
+
+	if keep != nil {
+		for key := range row {
+			if !keep[key] {
+				delete(row, key)
+			}
+		}
+	}
+
+	return row, nil
+}
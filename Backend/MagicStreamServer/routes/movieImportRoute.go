@@ -0,0 +1,349 @@
+package routes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxImportBodyBytes caps the size of a single import upload
+const maxImportBodyBytes = 20 << 20
+
+// Import row outcomes
+const (
+	ImportStatusCreated = "created"
+	ImportStatusUpdated = "updated"
+	ImportStatusSkipped = "skipped"
+	ImportStatusError   = "error"
+)
+
+// ImportRowResult reports what happened to a single imported row
+type ImportRowResult struct {
+	Row    int    `json:"row" example:"1"`
+	ImdbID string `json:"imdb_id,omitempty" example:"tt0111161"`
+	Status string `json:"status" example:"created"`
+	Error  string `json:"error,omitempty" example:"Key: 'MovieCreateRequest.Title' Error:Field validation for 'Title' failed on the 'required' tag"`
+}
+
+// ImportReport summarises a bulk import
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run" example:"false"`
+	Total   int               `json:"total" example:"3"`
+	Created int               `json:"created" example:"1"`
+	Updated int               `json:"updated" example:"1"`
+	Skipped int               `json:"skipped" example:"0"`
+	Errors  int               `json:"errors" example:"1"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// importRow is a parsed row waiting to be validated
+type importRow struct {
+	number int
+	req    models.MovieCreateRequest
+	err    error
+}
+
+// Import godoc
+// @Summary      Bulk import movies
+// @Description  Import movies from a JSON array, NDJSON or CSV body, upserting by IMDb ID (Admin only). Rows are validated like POST /movies. The format is taken from the format query or the Content-Type header.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Accept       json
+// @Accept       plain
+// @Produce      json
+// @Param        format query string false "Body format: json, ndjson or csv"
+// @Param        dry_run query bool false "Validate and report without writing"
+// @Success      200 {object} ImportReport "Per-row import report"
+// @Failure      400 {object} ErrorResponse "Unreadable body or unsupported format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      413 {object} ErrorResponse "Import body too large"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /admin/movies/import [post]
+func (h *MovieHandler) Import(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 60*time.Second)
+	defer cancel()
+
+	format := importFormat(c)
+	dryRun := c.Query("dry_run") == "true"
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import body must be at most %d MB", maxImportBodyBytes>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import body", "details": err.Error()})
+		return
+	}
+
+	var rows []importRow
+	switch format {
+	case "json":
+		rows, err = parseJSONImport(body)
+	case "ndjson":
+		rows, err = parseNDJSONImport(body)
+	case "csv":
+		rows, err = parseCSVImport(body)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported import format. Use json, ndjson or csv"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse import body", "details": err.Error()})
+		return
+	}
+
+	genres, err := h.genreRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	catalog := make(map[int]models.Genre, len(genres))
+	for _, genre := range genres {
+		catalog[genre.GenreID] = genre
+	}
+
+	rankingList, err := h.rankingRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	rankings := make(map[int]models.Ranking, len(rankingList))
+	for _, ranking := range rankingList {
+		rankings[ranking.RankingValue] = ranking
+	}
+
+	report := ImportReport{DryRun: dryRun, Total: len(rows), Rows: make([]ImportRowResult, 0, len(rows))}
+	seen := make(map[string]int)
+
+	actor, _ := middleware.GetUserID(c)
+
+	for _, row := range rows {
+		result := h.importMovieRow(ctx, row, catalog, rankings, seen, dryRun, actor)
+
+		switch result.Status {
+		case ImportStatusCreated:
+			report.Created++
+		case ImportStatusUpdated:
+			report.Updated++
+		case ImportStatusSkipped:
+			report.Skipped++
+		default:
+			report.Errors++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// importMovieRow validates one row and upserts it unless this is a dry run
+func (h *MovieHandler) importMovieRow(ctx context.Context, row importRow, catalog map[int]models.Genre, rankings map[int]models.Ranking, seen map[string]int, dryRun bool, actor string) ImportRowResult {
+	result := ImportRowResult{Row: row.number, ImdbID: row.req.ImdbID}
+	fail := func(err error) ImportRowResult {
+		result.Status = ImportStatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	if row.err != nil {
+		return fail(row.err)
+	}
+
+	req := row.req
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return fail(err)
+	}
+
+	if first, ok := seen[req.ImdbID]; ok {
+		return fail(fmt.Errorf("duplicate imdb_id, already imported at row %d", first))
+	}
+	seen[req.ImdbID] = row.number
+
+	if !utils.ValidateGenres(req.Genre) {
+		return fail(errors.New("genres must be unique and have positive IDs"))
+	}
+	for i, genre := range req.Genre {
+		found, ok := catalog[genre.GenreID]
+		if !ok {
+			return fail(fmt.Errorf("unknown genre id %d", genre.GenreID))
+		}
+		req.Genre[i] = found
+	}
+
+	translations, err := h.locales.MovieTranslations(req.Translations)
+	if err != nil {
+		return fail(err)
+	}
+	req.Translations = translations
+
+	ranking, ok := rankings[req.Ranking.RankingValue]
+	if !ok {
+		return fail(fmt.Errorf("unknown ranking value %d", req.Ranking.RankingValue))
+	}
+	req.Ranking = ranking
+
+	credits, err := resolveCredits(ctx, h.personRepo, req.Credits)
+	if err != nil {
+		return fail(err)
+	}
+	req.Credits = credits
+
+	movie := req.ToMovie()
+
+	existing, err := h.movieRepo.FindByImdbID(ctx, req.ImdbID)
+	switch {
+	case err == nil:
+		movie.ID = existing.ID
+		movie.Version = existing.Version
+		movie.UpdatedAt = existing.UpdatedAt
+		movie.UserRating = existing.UserRating
+		keepPoster(existing, &movie)
+		movie.Videos = existing.Videos
+		movie.Renditions = existing.Renditions
+		movie.Subtitles = existing.Subtitles
+		// CSV has no translation or credits columns, so rows without any keep
+		// the stored ones. An explicit empty list still clears them.
+		if movie.Translations == nil {
+			movie.Translations = existing.Translations
+		}
+		if req.Credits == nil {
+			movie.Credits = existing.Credits
+		}
+		movie.Search = models.NewMovieSearchIndex(&movie)
+		if reflect.DeepEqual(*existing, movie) {
+			result.Status = ImportStatusSkipped
+			return result
+		}
+		result.Status = ImportStatusUpdated
+	case errors.Is(err, repositories.ErrMovieNotFound):
+		trashed, err := h.movieRepo.MovieExists(ctx, req.ImdbID)
+		if err != nil {
+			return fail(err)
+		}
+		if trashed {
+			return fail(errors.New("movie is in the trash, restore it before importing"))
+		}
+		result.Status = ImportStatusCreated
+	default:
+		return fail(err)
+	}
+
+	if dryRun {
+		return result
+	}
+
+	if _, err := h.movieRepo.UpsertByImdbID(ctx, &movie); err != nil {
+		return fail(err)
+	}
+
+	saved := &movie
+	if stored, err := h.movieRepo.FindByImdbID(ctx, movie.ImdbID); err == nil {
+		saved = stored
+	}
+	h.recordRevision(ctx, models.RevisionActionImport, actor, existing, saved)
+
+	return result
+}
+
+// importFormat picks the body format from the format query or the Content-Type header
+func importFormat(c *gin.Context) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return "ndjson"
+	case "text/csv", "application/csv":
+		return "csv"
+	default:
+		return "json"
+	}
+}
+
+func parseJSONImport(body []byte) ([]importRow, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, len(raw))
+	for i, item := range raw {
+		rows[i].number = i + 1
+		rows[i].err = json.Unmarshal(item, &rows[i].req)
+	}
+
+	return rows, nil
+}
+
+func parseNDJSONImport(body []byte) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), maxImportBodyBytes)
+
+	var rows []importRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := importRow{number: line}
+		row.err = json.Unmarshal(text, &row.req)
+		rows = append(rows, row)
+	}
+
+	return rows, scanner.Err()
+}
+
+// parseCSVImport reads the header row and then one movie per record; row
+// numbers count data records, so row 1 is the first line after the header
+func parseCSVImport(body []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []importRow
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		row := importRow{number: number}
+		if err != nil {
+			row.err = err
+		} else {
+			row.req, row.err = utils.MovieRequestFromCSV(header, record)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,218 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UploadPoster godoc
+// @Summary      Upload movie poster
+// @Description  Upload a JPEG, PNG or WebP poster as multipart field "poster". The image type is detected from its content. The server stores resized JPEG and WebP variants, lists them in poster.sizes and points poster_path at the largest JPEG (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Param        poster formData file true "Poster image (JPEG, PNG or WebP)"
+// @Success      200 {object} models.Movie "Movie with the uploaded poster"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Missing poster or invalid ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      413 {object} ErrorResponse "File or image dimensions too large"
+// @Failure      415 {object} ErrorResponse "Unsupported image type"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/poster [post]
+func (h *MovieHandler) UploadPoster(c *gin.Context) {
+	// Resizing and storing every variant takes longer than a plain write
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 30*time.Second)
+	defer cancel()
+
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+	id := objectID.Hex()
+
+	before, err := h.movieRepo.FindByID(ctx, id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	data, ok := h.readPoster(c)
+	if !ok {
+		return
+	}
+
+	if _, err := utils.SniffImageType(data); err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	width, height, renditions, err := utils.RenderPoster(data, h.cfg.PosterWidths)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrUnsupportedImage):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrImageTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process poster"})
+		}
+		return
+	}
+
+	// Keys are derived from the content, so a new upload never overwrites blobs
+	// that earlier revisions or cached pages still point at
+	sum := sha256.Sum256(data)
+	prefix := fmt.Sprintf("posters/%s/%s", id, hex.EncodeToString(sum[:8]))
+
+	poster := models.PosterImage{
+		Key:        prefix,
+		Width:      width,
+		Height:     height,
+		Sizes:      make([]models.PosterSize, 0, len(renditions)),
+		UploadedAt: time.Now(),
+	}
+	posterPath := ""
+	for _, rendition := range renditions {
+		extension := "jpg"
+		if rendition.Format == utils.PosterFormatWebP {
+			extension = "webp"
+		}
+		key := fmt.Sprintf("%s/w%d.%s", prefix, rendition.Width, extension)
+
+		if err := h.blobStore.Put(ctx, key, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), rendition.ContentType); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store poster"})
+			return
+		}
+
+		size := models.PosterSize{
+			Width:  rendition.Width,
+			Height: rendition.Height,
+			Format: rendition.Format,
+			URL:    h.blobStore.URL(key),
+		}
+		poster.Sizes = append(poster.Sizes, size)
+		if size.Format == utils.PosterFormatJPEG {
+			posterPath = size.URL // renditions come smallest first
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"poster": poster, "poster_path": posterPath}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	updatedMovie, err := h.movieRepo.FindByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated movie"})
+		return
+	}
+
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionUpdate, actor, before, updatedMovie)
+
+	c.Header("ETag", utils.MovieETag(updatedMovie.Version))
+	c.JSON(http.StatusOK, updatedMovie)
+}
+
+// readPoster reads the uploaded poster file within the configured size limit,
+// writing the error response itself when ok is false
+func (h *MovieHandler) readPoster(c *gin.Context) ([]byte, bool) {
+	maxBytes := int64(h.cfg.PosterMaxUploadMB) << 20
+	tooLarge := fmt.Sprintf("Poster must be at most %d MB", h.cfg.PosterMaxUploadMB)
+
+	// Leave room for the multipart headers around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	header, err := c.FormFile("poster")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Multipart field poster is required"})
+		return nil, false
+	}
+	if header.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read poster"})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read poster"})
+		return nil, false
+	}
+	return data, true
+}
+
+// keepPoster carries an uploaded poster over to a replacement of the movie as
+// long as poster_path still points at it
+func keepPoster(before, after *models.Movie) {
+	if before != nil && before.Poster != nil && after.PosterPath == before.PosterPath {
+		after.Poster = before.Poster
+	}
+}
+
+// movieReplacement builds the update writing every editable field of movie.
+// A movie without an uploaded poster or translations also drops any left from before.
+func movieReplacement(movie *models.Movie) (bson.M, error) {
+	fields, err := movieSetFields(movie)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": fields}
+	unset := bson.M{}
+	if movie.Poster == nil {
+		unset["poster"] = ""
+	}
+	if len(movie.Translations) == 0 {
+		unset["translations"] = ""
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
@@ -0,0 +1,380 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxPlaylistBytes bounds an uploaded media playlist, which is read into memory
+const maxPlaylistBytes = 1 << 20
+
+var (
+	hlsResolution = regexp.MustCompile(`^([1-9][0-9]{0,4})x([1-9][0-9]{0,4})$`)
+	hlsCodecs     = regexp.MustCompile(`^[A-Za-z0-9._-]+(,[A-Za-z0-9._-]+)*$`)
+)
+
+// UploadRendition godoc
+// @Summary      Upload HLS rendition
+// @Description  Upload one pre-encoded HLS rendition of a movie as multipart/form-data: the media playlist in field "playlist", every segment file (and init segment) in field "segments", plus the rendition metadata. Segments must be referenced by plain file name, as ffmpeg writes them. The server stores the segments and generates its own playlists; replacing a rendition removes the old segments (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        rendition path string true "Rendition name, lowercase letters, digits, - and _ (e.g. 1080p)"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Param        playlist formData file true "Media playlist (.m3u8)"
+// @Param        segments formData file true "Segment files, repeat the field for each"
+// @Param        bandwidth formData int true "Peak bandwidth in bits per second"
+// @Param        average_bandwidth formData int false "Average bandwidth in bits per second"
+// @Param        resolution formData string true "Video resolution, e.g. 1920x1080"
+// @Param        codecs formData string false "RFC 6381 codecs, e.g. avc1.640028,mp4a.40.2"
+// @Param        frame_rate formData number false "Frames per second, e.g. 23.976"
+// @Success      200 {object} models.Movie "Rendition replaced"
+// @Success      201 {object} models.Movie "Rendition added"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid metadata, playlist or missing segments"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      413 {object} ErrorResponse "Upload too large"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/renditions/{rendition} [put]
+func (h *MovieHandler) UploadRendition(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, name, ok := h.findVideoMovie(ctx, c, "rendition")
+	if !ok {
+		return
+	}
+
+	// The name would shadow the subtitle playlists at /hls/{movie_id}/subtitles/
+	if name == "subtitles" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rendition name subtitles is reserved"})
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	maxBytes := int64(h.cfg.VideoMaxUploadMB) << 20
+	tooLarge := fmt.Sprintf("Upload must be at most %d MB", h.cfg.VideoMaxUploadMB)
+	if c.Request.ContentLength > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be multipart/form-data"})
+		return
+	}
+
+	id := before.ID.Hex()
+	playlistID := bson.NewObjectID()
+	prefix := hlsKeyPrefix(id, name, playlistID)
+
+	// Segments are streamed straight to the blob store as they arrive, so
+	// whatever was stored is removed again if the upload turns out invalid
+	var uploaded []string
+	files := map[string]bool{}
+	fail := func(status int, message string) {
+		h.deleteBlobsLater(uploaded)
+		c.JSON(status, gin.H{"error": message})
+	}
+
+	form := url.Values{}
+	var playlistData []byte
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				fail(http.StatusRequestEntityTooLarge, tooLarge)
+				return
+			}
+			fail(http.StatusBadRequest, "Malformed multipart body")
+			return
+		}
+
+		switch part.FormName() {
+		case "playlist":
+			playlistData, err = io.ReadAll(io.LimitReader(part, maxPlaylistBytes+1))
+			if err == nil && len(playlistData) > maxPlaylistBytes {
+				err = errors.New("playlist is too large")
+			}
+		case "segments":
+			file := part.FileName()
+			contentType, ok := utils.HLSSegmentContentType(file)
+			if !ok {
+				part.Close()
+				fail(http.StatusBadRequest, fmt.Sprintf("Unsupported segment file %q. Use .ts, .m4s, .mp4, .aac or .m4a", file))
+				return
+			}
+			if files[file] {
+				part.Close()
+				fail(http.StatusBadRequest, fmt.Sprintf("Segment %q was uploaded twice", file))
+				return
+			}
+
+			key := prefix + "/" + file
+			err = h.blobStore.Put(c.Request.Context(), key, part, -1, contentType)
+			if err == nil {
+				uploaded = append(uploaded, key)
+				files[file] = true
+			}
+		default:
+			var value []byte
+			value, err = io.ReadAll(io.LimitReader(part, 1024))
+			form.Add(part.FormName(), string(value))
+		}
+		part.Close()
+
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				fail(http.StatusRequestEntityTooLarge, tooLarge)
+				return
+			}
+			fail(http.StatusBadRequest, "Failed to read upload: "+err.Error())
+			return
+		}
+	}
+
+	rendition, err := hlsRenditionFromForm(form)
+	if err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if playlistData == nil {
+		fail(http.StatusBadRequest, "Multipart field playlist is required")
+		return
+	}
+	parsed, err := utils.ParseMediaPlaylist(playlistData)
+	if err != nil {
+		fail(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	referenced := map[string]bool{}
+	if parsed.InitSegment != "" {
+		referenced[parsed.InitSegment] = true
+	}
+	for _, segment := range parsed.Segments {
+		referenced[segment.URI] = true
+		rendition.Duration += segment.Duration
+	}
+	for file := range referenced {
+		if !files[file] {
+			fail(http.StatusBadRequest, fmt.Sprintf("Segment %q is listed in the playlist but was not uploaded", file))
+			return
+		}
+	}
+
+	var unused []string
+	for file := range files {
+		if !referenced[file] {
+			unused = append(unused, prefix+"/"+file)
+		}
+	}
+	h.deleteBlobsLater(unused)
+
+	playlist := models.HLSPlaylist{
+		ID:             playlistID,
+		MovieID:        before.ID,
+		Rendition:      name,
+		KeyPrefix:      prefix,
+		TargetDuration: parsed.TargetDuration,
+		InitSegment:    parsed.InitSegment,
+		Segments:       parsed.Segments,
+		CreatedAt:      time.Now(),
+	}
+	rendition.PlaylistID = playlistID
+	rendition.SegmentCount = len(parsed.Segments)
+	rendition.UploadedAt = playlist.CreatedAt
+
+	// The upload may have outlasted the first timeout
+	ctx, cancel = context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	if err := h.playlistRepo.Create(ctx, &playlist); err != nil {
+		fail(http.StatusInternalServerError, "Failed to save playlist")
+		return
+	}
+
+	update := bson.M{"$set": bson.M{"renditions." + name: rendition}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		h.deletePlaylistLater(playlistID)
+		utils.HandleError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if previous, replaced := before.Renditions[name]; replaced {
+		h.deletePlaylistLater(previous.PlaylistID)
+		status = http.StatusOK
+	}
+	h.respondVideoChange(ctx, c, before, status)
+}
+
+// DeleteRendition godoc
+// @Summary      Delete HLS rendition
+// @Description  Remove a rendition from a movie's HLS stream and delete its segments (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        rendition path string true "Rendition name"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Success      200 {object} models.Movie "Rendition removed"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID or rendition name"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie or rendition not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/renditions/{rendition} [delete]
+func (h *MovieHandler) DeleteRendition(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, name, ok := h.findVideoMovie(ctx, c, "rendition")
+	if !ok {
+		return
+	}
+
+	rendition, exists := before.Renditions[name]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rendition not found"})
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	var err error
+	id := before.ID.Hex()
+	update := bson.M{"$unset": bson.M{"renditions." + name: ""}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	h.deletePlaylistLater(rendition.PlaylistID)
+	h.respondVideoChange(ctx, c, before, http.StatusOK)
+}
+
+// hlsRenditionFromForm validates the metadata fields of a rendition upload
+func hlsRenditionFromForm(form url.Values) (models.HLSRendition, error) {
+	var req models.HLSRenditionRequest
+	if err := binding.MapFormWithTag(&req, form, "form"); err != nil {
+		return models.HLSRendition{}, err
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return models.HLSRendition{}, err
+	}
+
+	rendition := models.HLSRendition{
+		Bandwidth:        req.Bandwidth,
+		AverageBandwidth: req.AverageBandwidth,
+		Codecs:           req.Codecs,
+		FrameRate:        req.FrameRate,
+	}
+
+	// Quality caps go by the height
+	match := hlsResolution.FindStringSubmatch(req.Resolution)
+	if match == nil {
+		return models.HLSRendition{}, errors.New("resolution must look like 1920x1080")
+	}
+	rendition.Width, _ = strconv.Atoi(match[1])
+	rendition.Height, _ = strconv.Atoi(match[2])
+	if req.Codecs != "" && !hlsCodecs.MatchString(req.Codecs) {
+		return models.HLSRendition{}, errors.New("codecs must be a comma separated list such as avc1.640028,mp4a.40.2")
+	}
+
+	return rendition, nil
+}
+
+// hlsKeyPrefix returns the blob key prefix of one uploaded rendition
+func hlsKeyPrefix(movieID, rendition string, playlistID bson.ObjectID) string {
+	return fmt.Sprintf("hls/%s/%s/%s", movieID, rendition, playlistID.Hex())
+}
+
+// deletePlaylistLater removes a playlist that is no longer referenced along with
+// its segments. A rendition can have thousands of segments, so this runs in the
+// background.
+func (h *MovieHandler) deletePlaylistLater(id bson.ObjectID) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		playlist, err := h.playlistRepo.FindByID(ctx, id)
+		if err != nil {
+			log.Printf("failed to load playlist %s for cleanup: %v", id.Hex(), err)
+			return
+		}
+
+		for _, key := range playlist.BlobKeys() {
+			h.deleteBlob(ctx, key)
+		}
+
+		if err := h.playlistRepo.Delete(ctx, id); err != nil {
+			log.Printf("failed to delete playlist %s: %v", id.Hex(), err)
+		}
+	}()
+}
+
+// deleteBlobsLater removes files of a failed or partly unused upload in the background
+func (h *MovieHandler) deleteBlobsLater(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		for _, key := range keys {
+			h.deleteBlob(ctx, key)
+		}
+	}()
+}
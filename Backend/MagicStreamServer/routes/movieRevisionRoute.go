@@ -0,0 +1,364 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RevisionListResponse for Swagger documentation
+type RevisionListResponse struct {
+	Data       []models.MovieRevision `json:"data"`
+	Pagination PaginationInfo         `json:"pagination"`
+}
+
+// GetRevisions godoc
+// @Summary      List movie revisions
+// @Description  Retrieve the edit history of a movie, newest first (Admin only)
+// @Tags         Revisions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} RevisionListResponse "Revisions with pagination info"
+// @Failure      400 {object} ErrorResponse "Invalid ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/revisions [get]
+func (h *MovieHandler) GetRevisions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	movieID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	revisions, total, err := h.revisionRepo.FindByMovie(ctx, movieID, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       revisions,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// GetRevision godoc
+// @Summary      Get a movie revision
+// @Description  Retrieve a single revision including the movie snapshot (Admin only)
+// @Tags         Revisions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        rev path int true "Revision number"
+// @Success      200 {object} models.MovieRevision "Revision"
+// @Failure      400 {object} ErrorResponse "Invalid ID or revision"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Revision not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/revisions/{rev} [get]
+func (h *MovieHandler) GetRevision(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	movieID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+
+	revision, err := h.revisionRepo.FindByNumber(ctx, movieID, rev)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRevisions godoc
+// @Summary      Diff two movie revisions
+// @Description  Compare the movie state between two revisions field by field (Admin only)
+// @Tags         Revisions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        from query int true "Older revision number"
+// @Param        to query int true "Newer revision number"
+// @Success      200 {object} models.RevisionDiff "Field-level diff"
+// @Failure      400 {object} ErrorResponse "Invalid ID or revision"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Revision not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/revisions/diff [get]
+func (h *MovieHandler) DiffRevisions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	movieID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	from, fromErr := strconv.Atoi(c.Query("from"))
+	to, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both from and to revision numbers are required"})
+		return
+	}
+
+	fromRev, err := h.revisionRepo.FindByNumber(ctx, movieID, from)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	toRev, err := h.revisionRepo.FindByNumber(ctx, movieID, to)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	changes, err := utils.DiffMovies(&fromRev.Snapshot, &toRev.Snapshot)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.RevisionDiff{
+		MovieID: movieID,
+		From:    from,
+		To:      to,
+		Changes: changes,
+	})
+}
+
+// RevertRevision godoc
+// @Summary      Revert a movie to a revision
+// @Description  Restore every field of a movie to its state at the given revision, recording the revert as a new revision (Admin only)
+// @Tags         Revisions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        rev path int true "Revision number"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Success      200 {object} models.Movie "Reverted movie"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID, revision or stale credits"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie or revision not found"
+// @Failure      409 {object} ErrorResponse "IMDb ID now used by another movie, or movie changed during the revert"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/revisions/{rev}/revert [post]
+func (h *MovieHandler) RevertRevision(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	movieID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+
+	revision, err := h.revisionRepo.FindByNumber(ctx, movieID, rev)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	current, err := h.movieRepo.FindByID(ctx, id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, current)
+	if !ok {
+		return
+	}
+
+	// People may have been merged or renamed since the revision was taken
+	target := revision.Snapshot
+	credits, err := resolveCredits(ctx, h.personRepo, target.Credits)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	target.Credits = credits
+
+	// Rankings may have been renamed or reordered too
+	ranking, err := resolveRanking(ctx, h.rankingRepo, target.Ranking)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	target.Ranking = ranking
+
+	// Genre translations may have changed as well
+	genres, err := resolveGenres(ctx, h.genreRepo, target.Genre)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	target.Genre = genres
+
+	// The IMDb ID may have been given to another movie since
+	if target.ImdbID != current.ImdbID {
+		exists, err := h.movieRepo.MovieExists(ctx, target.ImdbID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check IMDb ID"})
+			return
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "IMDb ID of this revision is now used by another movie"})
+			return
+		}
+	}
+
+	update, err := movieReplacement(&target)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// The write is always pinned to the version read above, so the history
+	// entry diffs against the state the revert actually replaced
+	if err := h.movieRepo.UpdateVersioned(ctx, id, current.Version, update); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrVersionConflict) && conditional:
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was modified since it was read"})
+		case errors.Is(err, repositories.ErrVersionConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie was modified during the revert, retry"})
+		default:
+			utils.HandleError(c, err)
+		}
+		return
+	}
+
+	reverted, err := h.movieRepo.FindByID(ctx, id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionRevert, actor, current, reverted, rev)
+
+	c.Header("ETag", utils.MovieETag(reverted.Version))
+	c.JSON(http.StatusOK, reverted)
+}
+
+// revisionNumberAttempts bounds how often recordRevision renumbers a revision
+// whose number was taken by a concurrent write
+const revisionNumberAttempts = 5
+
+// recordRevision appends a history entry for a movie write. The write has already
+// happened at this point, so a failure is logged instead of failing the request.
+func (h *MovieHandler) recordRevision(ctx context.Context, action, actor string, before, after *models.Movie, revertedFrom ...int) {
+	state := after
+	if state == nil {
+		state = before
+	}
+
+	changes, err := utils.DiffMovies(before, after)
+	if err != nil {
+		log.Printf("failed to diff movie %s for revision: %v", state.ID.Hex(), err)
+		return
+	}
+
+	revision := models.MovieRevision{
+		MovieID:  state.ID,
+		Action:   action,
+		Actor:    actor,
+		Changes:  changes,
+		Snapshot: *state,
+	}
+	if len(revertedFrom) > 0 {
+		revision.RevertedFrom = revertedFrom[0]
+	}
+
+	// A concurrent write to the same movie can take the number first
+	for attempt := 0; attempt < revisionNumberAttempts; attempt++ {
+		latest, err := h.revisionRepo.LatestNumber(ctx, state.ID)
+		if err != nil {
+			log.Printf("failed to number revision for movie %s: %v", state.ID.Hex(), err)
+			return
+		}
+
+		revision.ID = bson.NewObjectID()
+		revision.Revision = latest + 1
+		revision.CreatedAt = time.Now()
+		err = h.revisionRepo.Create(ctx, &revision)
+		if errors.Is(err, repositories.ErrRevisionExists) {
+			continue
+		}
+		if err != nil {
+			log.Printf("failed to record revision for movie %s: %v", state.ID.Hex(), err)
+		}
+		return
+	}
+
+	log.Printf("failed to record revision for movie %s: revision number kept being taken", state.ID.Hex())
+}
+
+// movieSetFields turns a movie into a $set document of its editable fields
+func movieSetFields(movie *models.Movie) (bson.M, error) {
+	raw, err := bson.Marshal(movie)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "_id")
+	delete(fields, "version")
+	delete(fields, "updated_at")
+	delete(fields, "user_rating")
+	delete(fields, "deleted_at")
+	delete(fields, "deleted_by")
+	delete(fields, "videos")
+	delete(fields, "renditions")
+	delete(fields, "subtitles")
+	// Rebuilt from the text being written, snapshots may predate it
+	fields["search"] = models.NewMovieSearchIndex(movie)
+
+	return fields, nil
+}
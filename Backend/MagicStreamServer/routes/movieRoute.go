@@ -1,57 +1,100 @@
 package routes
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
 	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
-	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/database"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
 	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/storage"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go.mongodb.org/mongo-driver/v2/bson"
-	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // MovieHandler handles movie-related requests
 type MovieHandler struct {
-	tokenService *authservice.TokenService
-	movieRepo    repositories.MovieRepository
-	genreRepo    repositories.GenreRepository
+	tokenService  *authservice.TokenService
+	cfg           *config.Config
+	movieRepo     repositories.MovieRepository
+	genreRepo     repositories.GenreRepository
+	personRepo    repositories.PersonRepository
+	revisionRepo  repositories.MovieRevisionRepository
+	rankingRepo   repositories.RankingRepository
+	watchlistRepo repositories.WatchlistRepository
+	blobStore     storage.BlobStore
+	playlistRepo  repositories.HLSPlaylistRepository
+	locales       *utils.Locales
 }
 
 // NewMovieHandler creates a new movie handler with dependencies injected
-func NewMovieHandler(ts *authservice.TokenService, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository) *MovieHandler {
+func NewMovieHandler(ts *authservice.TokenService, cfg *config.Config, movieRepo repositories.MovieRepository, genreRepo repositories.GenreRepository, personRepo repositories.PersonRepository, revisionRepo repositories.MovieRevisionRepository, rankingRepo repositories.RankingRepository, watchlistRepo repositories.WatchlistRepository, blobStore storage.BlobStore, playlistRepo repositories.HLSPlaylistRepository, locales *utils.Locales) *MovieHandler {
 	return &MovieHandler{
-		tokenService: ts,
-		movieRepo:    movieRepo,
-		genreRepo:    genreRepo,
+		tokenService:  ts,
+		cfg:           cfg,
+		movieRepo:     movieRepo,
+		genreRepo:     genreRepo,
+		personRepo:    personRepo,
+		revisionRepo:  revisionRepo,
+		rankingRepo:   rankingRepo,
+		watchlistRepo: watchlistRepo,
+		blobStore:     blobStore,
+		playlistRepo:  playlistRepo,
+		locales:       locales,
 	}
 }
 
 // GetAll godoc
 // @Summary      Get all movies
-// @Description  Retrieve list of all movies with optional filtering and pagination
+// @Description  Retrieve list of all movies with optional filtering and pagination. Titles, synopses, reviews and genre names are in the negotiated locale. Signed-in callers also get in_watchlist on each movie.
 // @Tags         Movies
 // @Produce      json
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
 // @Param        genre query string false "Filter by genre name"
-// @Param        ranking query int false "Filter by minimum ranking value"
+// @Param        ranking query int false "Only movies ranked at least as well as this ranking value"
+// @Param        year_from query int false "Filter by minimum release year"
+// @Param        year_to query int false "Filter by maximum release year"
+// @Param        min_runtime query int false "Filter by minimum runtime in minutes"
+// @Param        max_runtime query int false "Filter by maximum runtime in minutes"
+// @Param        language query string false "Filter by original or spoken language (e.g. en)"
+// @Param        subtitle query string false "Filter by subtitle language, declared or with an uploaded track (e.g. id)"
+// @Param        country query string false "Filter by country code (e.g. US)"
+// @Param        certification query string false "Filter by age certification (G, PG, PG-13, R, NC-17, NR)"
+// @Param        director query string false "Filter by director name"
+// @Param        cast query string false "Filter by cast member name"
+// @Param        min_rating query number false "Filter by minimum average user rating (1-10)"
+// @Param        min_ratings query int false "Filter by minimum number of user ratings"
+// @Param        sort query string false "ranking (best ranked first, default), rating (highest rated) or popular (most rated)"
 // @Param        limit query int false "Limit results (default 10, max 100)"
 // @Param        skip query int false "Skip results for pagination (default 0)"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Param        If-Modified-Since header string false "Last-Modified of a cached copy"
 // @Success      200 {object} MovieListResponse "List of movies with pagination info"
+// @Success      304 "Cached copy is still current"
+// @Header       200 {string} ETag "Catalog version tag"
+// @Header       200 {string} Last-Modified "Time of the latest catalog change"
+// @Header       200 {string} Content-Language "Locale of the movie text"
+// @Failure      400 {object} ErrorResponse "Invalid filter"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies [get]
 func (h *MovieHandler) GetAll(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
 	defer cancel()
 
 	// Parse query parameters
-	genreFilter := c.Query("genre")
-	rankingFilter := c.Query("ranking")
 	limitStr := c.DefaultQuery("limit", "10")
 	skipStr := c.DefaultQuery("skip", "0")
 
@@ -66,20 +109,38 @@ func (h *MovieHandler) GetAll(c *gin.Context) {
 		skip = 0
 	}
 
-	// Build filter
-	filter := bson.M{}
-	if genreFilter != "" {
-		filter["genre.genre_name"] = bson.M{"$regex": genreFilter, "$options": "i"}
+	filter, err := h.movieListFilter(ctx, c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
 	}
-	if rankingFilter != "" {
-		rankingValue, _ := strconv.Atoi(rankingFilter)
-		filter["ranking.ranking_value"] = bson.M{"$gte": rankingValue}
+
+	sort, err := movieQuerySort(c.Request.URL.Query())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
 	}
 
-	moviesColl := database.OpenCollection("movies")
+	chain := middleware.GetLocaleChain(c)
+	utils.ContentLanguage(c, chain)
+
+	profileID, signedIn := middleware.GetProfileID(c)
+	if signedIn {
+		utils.Personalized(c)
+	} else {
+		state, err := h.movieRepo.State(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movies"})
+			return
+		}
+		c.Writer.Header().Add("Vary", "Authorization")
+		if utils.NotModified(c, middleware.GetCacheControl(c), utils.CollectionETag(state, c.Request.URL.RequestURI()+utils.LocaleParams(chain)), state.LastModified) {
+			return
+		}
+	}
 
 	// Get total count for pagination
-	totalCount, err := moviesColl.CountDocuments(ctx, filter)
+	totalCount, err := h.movieRepo.Count(ctx, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count movies"})
 		return
@@ -89,90 +150,234 @@ func (h *MovieHandler) GetAll(c *gin.Context) {
 	opts := options.Find().
 		SetLimit(int64(limit)).
 		SetSkip(int64(skip)).
-		SetSort(bson.M{"ranking.ranking_value": -1}) // Sort by ranking descending
+		SetSort(sort)
 
-	cursor, err := moviesColl.Find(ctx, filter, opts)
+	movies, err := h.movieRepo.FindAll(ctx, filter, opts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movies"})
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var movies []models.Movie
-	if err := cursor.All(ctx, &movies); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse movies"})
-		return
+	if signedIn {
+		if err := h.markWatchlist(ctx, profileID, movies); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+			return
+		}
 	}
+	localizeMovies(movies, chain)
 
 	// Return with pagination info
 	c.JSON(http.StatusOK, gin.H{
 		"data": movies,
 		"pagination": gin.H{
-			"total":       totalCount,
-			"limit":       limit,
-			"skip":        skip,
-			"total_pages": (totalCount + int64(limit) - 1) / int64(limit),
+			"total":        totalCount,
+			"limit":        limit,
+			"skip":         skip,
+			"total_pages":  (totalCount + int64(limit) - 1) / int64(limit),
 			"current_page": (skip / limit) + 1,
 		},
 	})
 }
 
+// GetFacets godoc
+// @Summary      Get movie facet counts
+// @Description  Count movies per genre and per ranking for the browse filters. Accepts the same filters as GET /movies; each facet ignores its own selected value. Genre names are in the negotiated locale.
+// @Tags         Movies
+// @Produce      json
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Param        genre query string false "Filter by genre name"
+// @Param        ranking query int false "Only movies ranked at least as well as this ranking value"
+// @Param        year_from query int false "Filter by minimum release year"
+// @Param        year_to query int false "Filter by maximum release year"
+// @Param        min_runtime query int false "Filter by minimum runtime in minutes"
+// @Param        max_runtime query int false "Filter by maximum runtime in minutes"
+// @Param        language query string false "Filter by original or spoken language (e.g. en)"
+// @Param        subtitle query string false "Filter by subtitle language, declared or with an uploaded track (e.g. id)"
+// @Param        country query string false "Filter by country code (e.g. US)"
+// @Param        certification query string false "Filter by age certification (G, PG, PG-13, R, NC-17, NR)"
+// @Param        director query string false "Filter by director name"
+// @Param        cast query string false "Filter by cast member name"
+// @Success      200 {object} models.MovieFacets "Facet counts"
+// @Failure      400 {object} ErrorResponse "Invalid filter"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/facets [get]
+func (h *MovieHandler) GetFacets(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	metadata, err := movieMetadataFilter(c.Request.URL.Query())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	rankingOrder, err := rankingOrderFilter(ctx, h.rankingRepo, c.Query("ranking"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Metadata filters narrow every facet, so they go on both sides
+	genreFilter := utils.ApplyMovieMetadataFilter(utils.BuildMovieFilter(c.Query("genre"), 0), metadata)
+	rankingFilter := utils.ApplyMovieMetadataFilter(utils.BuildMovieFilter("", rankingOrder), metadata)
+
+	facets, err := h.movieRepo.Facets(ctx, genreFilter, rankingFilter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count movie facets"})
+		return
+	}
+
+	chain := middleware.GetLocaleChain(c)
+	if len(chain) > 1 {
+		genres, err := h.genreRepo.FindAll(ctx)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		names := make(map[int]string, len(genres))
+		for _, genre := range genres {
+			genre.Localize(chain)
+			names[genre.GenreID] = genre.GenreName
+		}
+		for i, bucket := range facets.Genres {
+			if name, ok := names[bucket.Value]; ok {
+				facets.Genres[i].Name = name
+			}
+		}
+	}
+	utils.ContentLanguage(c, chain)
+
+	c.JSON(http.StatusOK, facets)
+}
+
 // GetByID godoc
 // @Summary      Get movie by ID
-// @Description  Retrieve a single movie by its MongoDB ObjectID or IMDb ID
+// @Description  Retrieve a single movie by its MongoDB ObjectID or IMDb ID, with its text in the negotiated locale. Signed-in callers also get in_watchlist.
 // @Tags         Movies
 // @Produce      json
 // @Param        id path string true "Movie ID (ObjectID or IMDb ID)"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
 // @Success      200 {object} models.Movie "Movie details"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Param        If-Modified-Since header string false "Last-Modified of a cached copy"
+// @Success      304 "Cached copy is still current"
+// @Header       200 {string} ETag "Movie version and locale tag, also used for If-Match"
+// @Header       200 {string} Last-Modified "Time of the latest change"
+// @Header       200 {string} Content-Language "Locale of the movie text"
 // @Failure      400 {object} ErrorResponse "Invalid ID format"
 // @Failure      404 {object} ErrorResponse "Movie not found"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies/{id} [get]
 func (h *MovieHandler) GetByID(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
 	defer cancel()
 
-	id := c.Param("id")
-	moviesColl := database.OpenCollection("movies")
+	movie, err := h.findMovie(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
+		return
+	}
 
-	var movie models.Movie
-	var err error
+	// Each locale is its own representation, so it gets its own ETag. The
+	// version in it still works for If-Match.
+	chain := middleware.GetLocaleChain(c)
+	utils.ContentLanguage(c, chain)
+	movie.Localize(chain)
+	etag := utils.LocalizedMovieETag(movie.Version, chain)
 
-	// Try to find by ObjectID first
-	objectID, parseErr := bson.ObjectIDFromHex(id)
-	if parseErr == nil {
-		// Valid ObjectID format, search by _id
-		err = moviesColl.FindOne(ctx, bson.M{"_id": objectID}).Decode(&movie)
-	} else {
-		// Not a valid ObjectID, try IMDb ID
-		err = moviesColl.FindOne(ctx, bson.M{"imdb_id": id}).Decode(&movie)
+	profileID, signedIn := middleware.GetProfileID(c)
+	if !signedIn {
+		c.Writer.Header().Add("Vary", "Authorization")
+		if utils.NotModified(c, middleware.GetCacheControl(c), etag, movie.UpdatedAt) {
+			return
+		}
+		c.JSON(http.StatusOK, movie)
+		return
+	}
+
+	utils.Personalized(c)
+	c.Header("ETag", etag)
+
+	movies := []models.Movie{*movie}
+	if err := h.markWatchlist(ctx, profileID, movies); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+		return
 	}
 
+	c.JSON(http.StatusOK, movies[0])
+}
+
+// GetCredits godoc
+// @Summary      Get movie credits
+// @Description  Retrieve the cast and crew of a movie with full person details
+// @Tags         Movies
+// @Produce      json
+// @Param        id path string true "Movie ID (ObjectID or IMDb ID)"
+// @Success      200 {array} models.CreditDetail "Movie credits"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/credits [get]
+func (h *MovieHandler) GetCredits(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	movie, err := h.findMovie(ctx, c.Param("id"))
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		utils.HandleError(c, err)
+		return
+	}
+
+	ids := make([]bson.ObjectID, 0, len(movie.Credits))
+	for _, credit := range movie.Credits {
+		ids = append(ids, credit.PersonID)
+	}
+
+	people := map[bson.ObjectID]*models.Person{}
+	if len(ids) > 0 {
+		found, err := h.personRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			utils.HandleError(c, err)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
-		return
+		for i := range found {
+			people[found[i].ID] = &found[i]
+		}
+	}
+
+	credits := make([]models.CreditDetail, len(movie.Credits))
+	for i, credit := range movie.Credits {
+		credits[i] = models.CreditDetail{Credit: credit, Person: people[credit.PersonID]}
 	}
 
-	c.JSON(http.StatusOK, movie)
+	c.JSON(http.StatusOK, credits)
 }
 
 // GetByGenre godoc
 // @Summary      Get movies by genre
-// @Description  Retrieve movies filtered by specific genre
+// @Description  Retrieve movies filtered by specific genre, with their text in the negotiated locale
 // @Tags         Movies
 // @Produce      json
 // @Param        genre_id path int true "Genre ID"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
 // @Param        limit query int false "Limit results (default 10)"
 // @Param        skip query int false "Skip results (default 0)"
+// @Param        If-None-Match header string false "ETag of a cached copy"
+// @Param        If-Modified-Since header string false "Last-Modified of a cached copy"
 // @Success      200 {object} MovieListResponse "List of movies"
+// @Success      304 "Cached copy is still current"
+// @Header       200 {string} ETag "Catalog version tag"
+// @Header       200 {string} Last-Modified "Time of the latest catalog change"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies/genre/{genre_id} [get]
 func (h *MovieHandler) GetByGenre(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
 	defer cancel()
 
 	genreIDStr := c.Param("genre_id")
@@ -191,28 +396,33 @@ func (h *MovieHandler) GetByGenre(c *gin.Context) {
 		limit = 10
 	}
 
-	filter := bson.M{"genre.genre_id": genreID}
-	moviesColl := database.OpenCollection("movies")
+	chain := middleware.GetLocaleChain(c)
+	utils.ContentLanguage(c, chain)
 
-	totalCount, _ := moviesColl.CountDocuments(ctx, filter)
+	// Callers with a maturity limit see fewer movies, so their lists are never
+	// shared and the others vary by Authorization
+	if middleware.GetMaturityLimit(c) != "" {
+		utils.Personalized(c)
+	} else {
+		state, err := h.movieRepo.State(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movies"})
+			return
+		}
+		c.Writer.Header().Add("Vary", "Authorization")
+		if utils.NotModified(c, middleware.GetCacheControl(c), utils.CollectionETag(state, c.Request.URL.RequestURI()+utils.LocaleParams(chain)), state.LastModified) {
+			return
+		}
+	}
 
-	opts := options.Find().
-		SetLimit(int64(limit)).
-		SetSkip(int64(skip)).
-		SetSort(bson.M{"ranking.ranking_value": -1})
+	totalCount, _ := h.movieRepo.Count(ctx, bson.M{"genre.genre_id": genreID})
 
-	cursor, err := moviesColl.Find(ctx, filter, opts)
+	movies, err := h.movieRepo.FindByGenre(ctx, genreID, limit, skip)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movies"})
 		return
 	}
-	defer cursor.Close(ctx)
-
-	var movies []models.Movie
-	if err := cursor.All(ctx, &movies); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse movies"})
-		return
-	}
+	localizeMovies(movies, chain)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data": movies,
@@ -226,72 +436,54 @@ func (h *MovieHandler) GetByGenre(c *gin.Context) {
 
 // GetRecommendedForUser godoc
 // @Summary      Get recommended movies for user
-// @Description  Get movies based on user's favorite genres
+// @Description  Get movies based on the favorite genres of the active profile, with their text in the negotiated locale
 // @Tags         Movies
 // @Security     BearerAuth
 // @Produce      json
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
 // @Param        limit query int false "Limit results (default 20)"
 // @Success      200 {array} models.Movie "Recommended movies"
 // @Failure      401 {object} ErrorResponse "Unauthorized"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies/recommendations [get]
 func (h *MovieHandler) GetRecommendedForUser(c *gin.Context) {
-	userID, ok := middleware.GetUserID(c)
+	profile, ok := middleware.GetProfile(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
 	defer cancel()
 
-	// Get user's favorite genres
-	usersColl := database.OpenCollection("users")
-	var user models.User
-	err := usersColl.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user data"})
-		return
-	}
-
-	if len(user.FavouriteGenres) == 0 {
+	if len(profile.FavouriteGenres) == 0 {
 		c.JSON(http.StatusOK, []models.Movie{})
 		return
 	}
 
 	// Extract genre IDs
-	genreIDs := make([]int, len(user.FavouriteGenres))
-	for i, genre := range user.FavouriteGenres {
+	genreIDs := make([]int, len(profile.FavouriteGenres))
+	for i, genre := range profile.FavouriteGenres {
 		genreIDs[i] = genre.GenreID
 	}
 
-	// Find movies matching user's favorite genres
+	// Find movies matching the profile's favorite genres
 	limitStr := c.DefaultQuery("limit", "20")
 	limit, _ := strconv.Atoi(limitStr)
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
 
-	filter := bson.M{"genre.genre_id": bson.M{"$in": genreIDs}}
-	moviesColl := database.OpenCollection("movies")
-
-	opts := options.Find().
-		SetLimit(int64(limit)).
-		SetSort(bson.M{"ranking.ranking_value": -1})
-
-	cursor, err := moviesColl.Find(ctx, filter, opts)
+	movies, err := h.movieRepo.FindByGenres(ctx, genreIDs, limit, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recommendations"})
 		return
 	}
-	defer cursor.Close(ctx)
-
-	var movies []models.Movie
-	if err := cursor.All(ctx, &movies); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse movies"})
-		return
-	}
 
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
 	c.JSON(http.StatusOK, movies)
 }
 
@@ -320,56 +512,77 @@ func (h *MovieHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Validate genres exist
-	if valid, err := ValidateGenres(ctx, req.Genre); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate genres"})
+	genres, err := resolveGenres(ctx, h.genreRepo, req.Genre)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
-	} else if !valid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "One or more genres are invalid"})
+	}
+	req.Genre = genres
+
+	translations, err := h.locales.MovieTranslations(req.Translations)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
+	req.Translations = translations
 
-	moviesColl := database.OpenCollection("movies")
+	credits, err := resolveCredits(ctx, h.personRepo, req.Credits)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	req.Credits = credits
 
-	// Check if movie with same IMDb ID already exists
-	var existingMovie models.Movie
-	err := moviesColl.FindOne(ctx, bson.M{"imdb_id": req.ImdbID}).Decode(&existingMovie)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Movie with this IMDb ID already exists"})
+	ranking, err := resolveRanking(ctx, h.rankingRepo, req.Ranking)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
+	req.Ranking = ranking
 
 	// Convert request to movie
 	movie := req.ToMovie()
 
-	// Insert movie
-	_, err = moviesColl.InsertOne(ctx, movie)
+	// Insert movie; IMDb IDs of trashed movies stay taken until they are purged
+	err = h.movieRepo.Create(ctx, &movie)
 	if err != nil {
+		if errors.Is(err, repositories.ErrMovieAlreadyExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie with this IMDb ID already exists"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create movie"})
 		return
 	}
 
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionCreate, actor, nil, &movie)
+
 	c.JSON(http.StatusCreated, movie)
 }
 
 // Update godoc
-// @Summary      Update movie
-// @Description  Update an existing movie (Admin only)
+// @Summary      Replace movie
+// @Description  Replace every editable field of an existing movie; omitted optional fields are cleared (Admin only)
 // @Tags         Movies
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        id path string true "Movie ID"
-// @Param        movie body models.MovieUpdateRequest true "Updated movie data"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Param        movie body models.MovieUpdateRequest true "Complete movie data"
 // @Success      200 {object} models.Movie "Movie updated"
+// @Header       200 {string} ETag "New movie version tag"
 // @Failure      400 {object} ErrorResponse "Invalid request"
 // @Failure      401 {object} ErrorResponse "Unauthorized"
 // @Failure      403 {object} ErrorResponse "Admin access required"
 // @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      409 {object} ErrorResponse "IMDb ID already used by another movie"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies/{id} [put]
 func (h *MovieHandler) Update(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
 	defer cancel()
 
 	id := c.Param("id")
@@ -385,48 +598,209 @@ func (h *MovieHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Validate genres if provided
-	if len(req.Genre) > 0 {
-		if valid, err := ValidateGenres(ctx, req.Genre); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate genres"})
+	before, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 			return
-		} else if !valid {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "One or more genres are invalid"})
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	h.replaceMovie(ctx, c, before, &req, conditional)
+}
+
+// Patch godoc
+// @Summary      Patch movie
+// @Description  Partially update a movie with a JSON Merge Patch (RFC 7396, application/merge-patch+json or application/json) or a JSON Patch (RFC 6902, application/json-patch+json). Setting a member to null in a merge patch clears it. The patched movie must pass the same validation as a full replacement (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Param        patch body object true "Merge patch document or array of JSON Patch operations"
+// @Success      200 {object} models.Movie "Movie updated"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid patch or patched movie fails validation"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      409 {object} ErrorResponse "JSON Patch test failed or IMDb ID already used"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      415 {object} ErrorResponse "Unsupported patch format"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id} [patch]
+func (h *MovieHandler) Patch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	var applyPatch func(doc, patch []byte) ([]byte, error)
+	switch c.ContentType() {
+	case utils.MergePatchContentType, binding.MIMEJSON:
+		applyPatch = utils.ApplyMergePatch
+	case utils.JSONPatchContentType:
+		applyPatch = utils.ApplyJSONPatch
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json or application/json-patch+json"})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	before, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
+		return
 	}
 
-	moviesColl := database.OpenCollection("movies")
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
 
-	// Update movie
-	filter := bson.M{"_id": objectID}
-	update := bson.M{"$set": req.ToMap()}
+	// Patches apply to the editable fields only, so _id and version can't be touched
+	current, err := json.Marshal(models.NewMovieUpdateRequest(before))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode movie"})
+		return
+	}
 
-	result, err := moviesColl.UpdateOne(ctx, filter, update)
+	patched, err := applyPatch(current, patch)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+		if errors.Is(err, utils.ErrPatchTestFailed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	var req models.MovieUpdateRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Patched movie is invalid: " + err.Error()})
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.replaceMovie(ctx, c, before, &req, conditional)
+}
+
+// replaceMovie writes the validated request over every editable field of before
+// and responds with the stored movie. Shared by PUT and PATCH.
+func (h *MovieHandler) replaceMovie(ctx context.Context, c *gin.Context, before *models.Movie, req *models.MovieUpdateRequest, conditional bool) {
+	genres, err := resolveGenres(ctx, h.genreRepo, req.Genre)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	req.Genre = genres
+
+	translations, err := h.locales.MovieTranslations(req.Translations)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	req.Translations = translations
+
+	credits, err := resolveCredits(ctx, h.personRepo, req.Credits)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	req.Credits = credits
+
+	ranking, err := resolveRanking(ctx, h.rankingRepo, req.Ranking)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	req.Ranking = ranking
+
+	if req.ImdbID != before.ImdbID {
+		exists, err := h.movieRepo.MovieExists(ctx, req.ImdbID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check IMDb ID"})
+			return
+		}
+		if exists {
+			c.JSON(http.StatusConflict, gin.H{"error": "Movie with this IMDb ID already exists"})
+			return
+		}
+	}
+
+	movie := req.ToMovie(before.ID)
+	keepPoster(before, &movie)
+	update, err := movieReplacement(&movie)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode movie"})
+		return
+	}
+
+	id := before.ID.Hex()
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrMovieNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+		case errors.Is(err, repositories.ErrVersionConflict):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was modified since it was read"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update movie"})
+		}
 		return
 	}
 
 	// Get updated movie
-	var updatedMovie models.Movie
-	err = moviesColl.FindOne(ctx, filter).Decode(&updatedMovie)
+	updatedMovie, err := h.movieRepo.FindByID(ctx, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated movie"})
 		return
 	}
 
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionUpdate, actor, before, updatedMovie)
+
+	c.Header("ETag", utils.MovieETag(updatedMovie.Version))
 	c.JSON(http.StatusOK, updatedMovie)
 }
 
 // Delete godoc
 // @Summary      Delete movie
-// @Description  Delete a movie (Admin only)
+// @Description  Move a movie to the trash; it is purged after the retention period unless restored (Admin only)
 // @Tags         Movies
 // @Security     BearerAuth
 // @Produce      json
@@ -439,7 +813,7 @@ func (h *MovieHandler) Update(c *gin.Context) {
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /movies/{id} [delete]
 func (h *MovieHandler) Delete(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
 	defer cancel()
 
 	id := c.Param("id")
@@ -449,19 +823,148 @@ func (h *MovieHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	moviesColl := database.OpenCollection("movies")
-	result, err := moviesColl.DeleteOne(ctx, bson.M{"_id": objectID})
+	userID, _ := middleware.GetUserID(c)
+
+	before, err := h.movieRepo.FindByID(ctx, objectID.Hex())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete movie"})
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movie"})
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+	err = h.movieRepo.Delete(ctx, objectID.Hex(), userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete movie"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Movie deleted successfully"})
+	deleted := *before
+	now := time.Now()
+	deleted.DeletedAt = &now
+	deleted.DeletedBy = userID
+	h.recordRevision(ctx, models.RevisionActionDelete, userID, before, &deleted)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Movie moved to trash"})
+}
+
+// checkIfMatch enforces the If-Match precondition of a movie write against the
+// current movie. It reports whether the write must be conditional on the
+// current version, and writes the error response itself when ok is false.
+func (h *MovieHandler) checkIfMatch(c *gin.Context, current *models.Movie) (conditional bool, ok bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		if h.cfg.MovieRequireIfMatch {
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required. Use the ETag from GET /movies/:id"})
+			return false, false
+		}
+		return false, true
+	}
+
+	if !utils.MovieIfMatchSatisfied(ifMatch, current.Version) {
+		c.Header("ETag", utils.MovieETag(current.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was modified since it was read"})
+		return false, false
+	}
+
+	return true, true
+}
+
+// markWatchlist sets in_watchlist on each movie for the caller's active profile
+func (h *MovieHandler) markWatchlist(ctx context.Context, profileID bson.ObjectID, movies []models.Movie) error {
+	ids := make([]bson.ObjectID, len(movies))
+	for i := range movies {
+		ids[i] = movies[i].ID
+	}
+
+	saved, err := h.watchlistRepo.Contains(ctx, profileID, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range movies {
+		inWatchlist := saved[movies[i].ID]
+		movies[i].InWatchlist = &inWatchlist
+	}
+	return nil
+}
+
+// localizeMovies rewrites the text of each movie in the locale chain
+func localizeMovies(movies []models.Movie, chain models.LocaleChain) {
+	for i := range movies {
+		movies[i].Localize(chain)
+	}
+}
+
+// findMovie looks a movie up by its ObjectID, falling back to the IMDb ID
+func (h *MovieHandler) findMovie(ctx context.Context, id string) (*models.Movie, error) {
+	if _, err := bson.ObjectIDFromHex(id); err == nil {
+		return h.movieRepo.FindByID(ctx, id)
+	}
+	return h.movieRepo.FindByImdbID(ctx, id)
+}
+
+// movieListSorts maps the GetAll sort parameter to its sort document
+var movieListSorts = map[string]bson.D{
+	"ranking": {{Key: "ranking.order", Value: 1}},
+	"rating":  {{Key: "user_rating.average", Value: -1}, {Key: "user_rating.count", Value: -1}},
+	"popular": {{Key: "user_rating.count", Value: -1}, {Key: "user_rating.average", Value: -1}},
+}
+
+// movieListFilter builds the GetAll filter from the genre, ranking and metadata query parameters
+func (h *MovieHandler) movieListFilter(ctx context.Context, c *gin.Context) (bson.M, error) {
+	return movieQueryFilter(ctx, h.rankingRepo, c.Request.URL.Query())
+}
+
+// movieQueryFilter builds a movie filter from GetAll style query parameters.
+// Saved collection queries go through it too.
+func movieQueryFilter(ctx context.Context, rankingRepo repositories.RankingRepository, query url.Values) (bson.M, error) {
+	metadata, err := movieMetadataFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rankingOrder, err := rankingOrderFilter(ctx, rankingRepo, query.Get("ranking"))
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ApplyMovieMetadataFilter(utils.BuildMovieFilter(query.Get("genre"), rankingOrder), metadata), nil
+}
+
+// movieMetadataFilter reads and validates the metadata filters of GetAll style
+// query parameters
+func movieMetadataFilter(query url.Values) (models.MovieMetadataFilter, error) {
+	var metadata models.MovieMetadataFilter
+	if err := binding.MapFormWithTag(&metadata, query, "form"); err != nil {
+		return metadata, utils.NewAppError(http.StatusBadRequest, "Invalid filter", err.Error())
+	}
+	// Country codes are stored upper case, ?country=us means the same
+	metadata.Country = strings.ToUpper(metadata.Country)
+	if err := binding.Validator.ValidateStruct(&metadata); err != nil {
+		return metadata, utils.NewAppError(http.StatusBadRequest, "Invalid filter", err.Error())
+	}
+	return metadata, nil
+}
+
+// movieQuerySort returns the sort document of the sort query parameter, best ranked first by default
+func movieQuerySort(query url.Values) (bson.D, error) {
+	name := query.Get("sort")
+	if name == "" {
+		name = "ranking"
+	}
+
+	sort, ok := movieListSorts[name]
+	if !ok {
+		return nil, utils.NewAppError(http.StatusBadRequest, "Invalid sort. Use ranking, rating or popular", name)
+	}
+	return sort, nil
 }
 
 // MovieListResponse for Swagger documentation
@@ -477,4 +980,4 @@ type PaginationInfo struct {
 	Skip        int   `json:"skip"`
 	TotalPages  int64 `json:"total_pages"`
 	CurrentPage int   `json:"current_page"`
-}
\ No newline at end of file
+}
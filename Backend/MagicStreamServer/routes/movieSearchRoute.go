@@ -0,0 +1,175 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Search godoc
+// @Summary      Search movies
+// @Description  Full-text search over the titles and synopses of every locale, best matches first. Accepts the same filters as GET /movies. Results are in the negotiated locale whatever language the query was typed in.
+// @Tags         Movies
+// @Produce      json
+// @Param        q query string true "Search words (at least 2 characters)"
+// @Param        genre query string false "Filter by genre name"
+// @Param        ranking query int false "Only movies ranked at least as well as this ranking value"
+// @Param        year_from query int false "Filter by minimum release year"
+// @Param        year_to query int false "Filter by maximum release year"
+// @Param        language query string false "Filter by original or spoken language (e.g. en)"
+// @Param        country query string false "Filter by country code (e.g. US)"
+// @Param        certification query string false "Filter by age certification (G, PG, PG-13, R, NC-17, NR)"
+// @Param        limit query int false "Limit results (default 10, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Success      200 {object} MovieListResponse "Matching movies with pagination info"
+// @Header       200 {string} Content-Language "Locale of the movie text"
+// @Failure      400 {object} ErrorResponse "Missing query or invalid filter"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/search [get]
+func (h *MovieHandler) Search(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	query := strings.TrimSpace(c.Query("q"))
+	if utf8.RuneCountInString(query) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query q must be at least 2 characters"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	skip, _ := strconv.Atoi(c.DefaultQuery("skip", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	if skip < 0 {
+		skip = 0
+	}
+
+	filter, err := h.movieListFilter(ctx, c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	movies, total, err := h.movieRepo.Search(ctx, query, filter, int64(limit), int64(skip))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search movies"})
+		return
+	}
+
+	if profileID, signedIn := middleware.GetProfileID(c); signedIn {
+		utils.Personalized(c)
+		if err := h.markWatchlist(ctx, profileID, movies); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+			return
+		}
+	}
+
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": movies,
+		"pagination": gin.H{
+			"total":        total,
+			"limit":        limit,
+			"skip":         skip,
+			"total_pages":  (total + int64(limit) - 1) / int64(limit),
+			"current_page": (skip / limit) + 1,
+		},
+	})
+}
+
+// Autocomplete godoc
+// @Summary      Autocomplete movie titles
+// @Description  Suggest movies with a title word starting with the typed prefix, in any locale, best ranked first. Accents and case are ignored. Titles are in the negotiated locale; matched_title shows the title the prefix matched when it is a different one.
+// @Tags         Movies
+// @Produce      json
+// @Param        q query string true "Typed prefix"
+// @Param        limit query int false "Limit results (default 8, max 20)"
+// @Param        lang query string false "Response locale, overrides Accept-Language (e.g. id)"
+// @Param        Accept-Language header string false "Preferred locales"
+// @Success      200 {array} models.MovieSuggestion "Suggestions"
+// @Header       200 {string} Content-Language "Locale of the titles"
+// @Failure      400 {object} ErrorResponse "Missing query"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/autocomplete [get]
+func (h *MovieHandler) Autocomplete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	prefix := models.FoldSearchText(c.Query("q"))
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query q is required"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if limit <= 0 || limit > 20 {
+		limit = 8
+	}
+
+	movies, err := h.movieRepo.Autocomplete(ctx, prefix, int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suggestions"})
+		return
+	}
+
+	chain := middleware.GetLocaleChain(c)
+	suggestions := make([]models.MovieSuggestion, len(movies))
+	for i := range movies {
+		movie := &movies[i]
+		matched := matchedTitle(movie, prefix)
+		movie.Localize(chain)
+
+		suggestions[i] = models.MovieSuggestion{
+			ID:          movie.ID,
+			Title:       movie.Title,
+			ReleaseDate: movie.ReleaseDate,
+			PosterPath:  movie.PosterPath,
+		}
+		if matched != "" && !titleMatches(movie.Title, prefix) {
+			suggestions[i].MatchedTitle = matched
+		}
+	}
+
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// matchedTitle returns the title of the movie, default locale first, with a
+// word starting with the folded prefix
+func matchedTitle(movie *models.Movie, prefix string) string {
+	if titleMatches(movie.Title, prefix) {
+		return movie.Title
+	}
+
+	locales := make([]string, 0, len(movie.Translations))
+	for locale := range movie.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if title := movie.Translations[locale].Title; title != "" && titleMatches(title, prefix) {
+			return title
+		}
+	}
+	return ""
+}
+
+func titleMatches(title, prefix string) bool {
+	folded := models.FoldSearchText(title)
+	return strings.HasPrefix(folded, prefix) || strings.Contains(folded, " "+prefix)
+}
@@ -0,0 +1,289 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// UploadSubtitle godoc
+// @Summary      Upload movie subtitles
+// @Description  Upload an SRT or WebVTT file as the subtitle or caption track of a movie in one language, replacing any previous track of that language and kind. SRT is converted to WebVTT; files that are not UTF-8 are read as UTF-16 (with a byte order mark) or Windows-1252. Tracks are listed under subtitles on the movie and added to its HLS master playlist (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        lang path string true "BCP 47 language tag (e.g. id, en, pt-BR)"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Param        file formData file true "Subtitle file (SRT or WebVTT)"
+// @Param        label formData string false "Name shown in the player's track menu (default: the language's own name)"
+// @Param        kind formData string false "subtitles (default) or captions"
+// @Success      200 {object} models.Movie "Subtitle track replaced"
+// @Success      201 {object} models.Movie "Subtitle track added"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID, language tag or form fields"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      413 {object} ErrorResponse "Subtitle file too large"
+// @Failure      415 {object} ErrorResponse "Not an SRT or WebVTT file"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/subtitles/{lang} [put]
+func (h *MovieHandler) UploadSubtitle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, lang, ok := h.findSubtitleMovie(ctx, c)
+	if !ok {
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	data, ok := h.readSubtitle(c)
+	if !ok {
+		return
+	}
+
+	var req models.SubtitleUploadRequest
+	if err := c.ShouldBindWith(&req, binding.FormMultipart); err != nil {
+		utils.HandleError(c, utils.NewAppError(http.StatusBadRequest, "Invalid request data", err.Error()))
+		return
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = models.SubtitleKindSubtitles
+	}
+	label := req.Label
+	if label == "" {
+		label = subtitleLabel(lang, kind)
+	}
+
+	vtt, _, err := utils.SubtitleToWebVTT(data)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Keys are derived from the content, so players holding the old URL keep working
+	id := before.ID.Hex()
+	sum := sha256.Sum256(vtt)
+	track := models.SubtitleTrack{
+		Lang:       lang,
+		Label:      label,
+		Kind:       kind,
+		Key:        fmt.Sprintf("subtitles/%s/%s/%s-%s.vtt", id, lang, kind, hex.EncodeToString(sum[:8])),
+		UploadedAt: time.Now(),
+	}
+	track.URL = h.blobStore.URL(track.Key)
+
+	if err := h.blobStore.Put(ctx, track.Key, bytes.NewReader(vtt), int64(len(vtt)), utils.WebVTTContentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store subtitles"})
+		return
+	}
+
+	var previous *models.SubtitleTrack
+	tracks := make([]models.SubtitleTrack, 0, len(before.Subtitles)+1)
+	for i, existing := range before.Subtitles {
+		if existing.Lang == lang && existing.Kind == kind {
+			previous = &before.Subtitles[i]
+			continue
+		}
+		tracks = append(tracks, existing)
+	}
+	tracks = append(tracks, track)
+	sort.SliceStable(tracks, func(i, j int) bool {
+		if tracks[i].Lang != tracks[j].Lang {
+			return tracks[i].Lang < tracks[j].Lang
+		}
+		return tracks[i].Kind > tracks[j].Kind // subtitles before captions
+	})
+
+	update := bson.M{"$set": bson.M{"subtitles": tracks}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		if previous == nil || previous.Key != track.Key {
+			h.deleteBlob(ctx, track.Key)
+		}
+		utils.HandleError(c, err)
+		return
+	}
+
+	if previous != nil && previous.Key != track.Key {
+		h.deleteBlob(ctx, previous.Key)
+	}
+
+	status := http.StatusCreated
+	if previous != nil {
+		status = http.StatusOK
+	}
+	h.respondVideoChange(ctx, c, before, status)
+}
+
+// DeleteSubtitle godoc
+// @Summary      Delete movie subtitles
+// @Description  Remove a subtitle or caption track from a movie and delete its file (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        lang path string true "BCP 47 language tag"
+// @Param        kind query string false "subtitles (default) or captions"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Success      200 {object} models.Movie "Subtitle track removed"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID or language tag"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie or subtitle track not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/subtitles/{lang} [delete]
+func (h *MovieHandler) DeleteSubtitle(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, lang, ok := h.findSubtitleMovie(ctx, c)
+	if !ok {
+		return
+	}
+
+	kind := c.DefaultQuery("kind", models.SubtitleKindSubtitles)
+	var track *models.SubtitleTrack
+	for i, existing := range before.Subtitles {
+		if existing.Lang == lang && existing.Kind == kind {
+			track = &before.Subtitles[i]
+			break
+		}
+	}
+	if track == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subtitle track not found"})
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	var err error
+	id := before.ID.Hex()
+	update := bson.M{"$pull": bson.M{"subtitles": bson.M{"lang": lang, "kind": kind}}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	h.deleteBlob(ctx, track.Key)
+	h.respondVideoChange(ctx, c, before, http.StatusOK)
+}
+
+// findSubtitleMovie loads the movie and canonicalizes the language tag in the
+// path, writing the error response itself when ok is false
+func (h *MovieHandler) findSubtitleMovie(ctx context.Context, c *gin.Context) (*models.Movie, string, bool) {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return nil, "", false
+	}
+
+	tag, err := language.Parse(c.Param("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid language tag, use BCP 47 such as id or pt-BR"})
+		return nil, "", false
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return nil, "", false
+	}
+
+	return movie, tag.String(), true
+}
+
+// readSubtitle reads the uploaded subtitle file within the configured size
+// limit, writing the error response itself when ok is false
+func (h *MovieHandler) readSubtitle(c *gin.Context) ([]byte, bool) {
+	maxBytes := int64(h.cfg.SubtitleMaxUploadKB) << 10
+	tooLarge := fmt.Sprintf("Subtitle file must be at most %d KB", h.cfg.SubtitleMaxUploadKB)
+
+	// Leave room for the multipart headers and form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+64<<10)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Multipart field file is required"})
+		return nil, false
+	}
+	if header.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read subtitles"})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read subtitles"})
+		return nil, false
+	}
+	return data, true
+}
+
+// subtitleLabel names a track after its language in that language, the way
+// players list them
+func subtitleLabel(lang, kind string) string {
+	label := lang
+	if tag, err := language.Parse(lang); err == nil {
+		if name := display.Self.Name(tag); name != "" {
+			label = name
+		}
+	}
+	if kind == models.SubtitleKindCaptions {
+		label += " (CC)"
+	}
+	return label
+}
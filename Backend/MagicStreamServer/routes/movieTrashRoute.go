@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// GetTrash godoc
+// @Summary      List trashed movies
+// @Description  Retrieve soft-deleted movies, most recently deleted first (Admin only)
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} MovieListResponse "Trashed movies with pagination info"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /admin/movies/trash [get]
+func (h *MovieHandler) GetTrash(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	movies, total, err := h.movieRepo.FindDeleted(ctx, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       movies,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// Restore godoc
+// @Summary      Restore a trashed movie
+// @Description  Take a soft-deleted movie out of the trash (Admin only)
+// @Tags         Admin
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Success      200 {object} models.Movie "Restored movie"
+// @Failure      400 {object} ErrorResponse "Invalid ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found in trash"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /admin/movies/{id}/restore [post]
+func (h *MovieHandler) Restore(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	if _, err := bson.ObjectIDFromHex(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	trashed, err := h.movieRepo.FindDeletedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found in trash"})
+			return
+		}
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.movieRepo.Restore(ctx, id); err != nil {
+		if errors.Is(err, repositories.ErrMovieNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found in trash"})
+			return
+		}
+		utils.HandleError(c, err)
+		return
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionRestore, actor, trashed, movie)
+
+	c.JSON(http.StatusOK, movie)
+}
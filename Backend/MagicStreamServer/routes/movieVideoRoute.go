@@ -0,0 +1,271 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// videoAssetName restricts asset and rendition names to path and field safe identifiers
+var videoAssetName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// videoExtensions lists the accepted video types, detected from the upload content
+var videoExtensions = map[string]string{
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// UploadVideo godoc
+// @Summary      Upload movie video
+// @Description  Upload an MP4 or WebM file as the raw request body and store it as the named video asset of the movie, replacing any previous file of that asset. The type is detected from the content, the resolution is given in the query and checked against each plan's quality cap. Assets are streamed from GET /stream/{movie_id}/{asset} (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Accept       video/mp4
+// @Accept       video/webm
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        asset path string true "Asset name, lowercase letters, digits, - and _ (e.g. main, trailer)"
+// @Param        resolution query string true "Video resolution, e.g. 1920x1080"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Success      200 {object} models.Movie "Video asset replaced"
+// @Success      201 {object} models.Movie "Video asset added"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID, asset name, resolution or empty body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      413 {object} ErrorResponse "Video too large"
+// @Failure      415 {object} ErrorResponse "Unsupported video type"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/videos/{asset} [put]
+func (h *MovieHandler) UploadVideo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, name, ok := h.findVideoMovie(ctx, c, "asset")
+	if !ok {
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	// Progressive files can't be narrowed down to a quality like HLS, so
+	// plans are checked against the resolution of the whole file
+	match := hlsResolution.FindStringSubmatch(c.Query("resolution"))
+	if match == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "resolution is required and must look like 1920x1080"})
+		return
+	}
+
+	maxBytes := int64(h.cfg.VideoMaxUploadMB) << 20
+	tooLarge := fmt.Sprintf("Video must be at most %d MB", h.cfg.VideoMaxUploadMB)
+	if c.Request.ContentLength > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must contain the video file"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read video"})
+		return
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	extension, ok := videoExtensions[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported video type, use MP4 or WebM"})
+		return
+	}
+
+	// Each upload gets a fresh key, so a replaced file never changes under a
+	// stream that is still reading it
+	id := before.ID.Hex()
+	asset := models.VideoAsset{
+		Key:         fmt.Sprintf("videos/%s/%s/%s%s", id, name, bson.NewObjectID().Hex(), extension),
+		ContentType: contentType,
+		UploadedAt:  time.Now(),
+	}
+	asset.Width, _ = strconv.Atoi(match[1])
+	asset.Height, _ = strconv.Atoi(match[2])
+
+	// Large files take far longer than the usual request timeout, so the upload
+	// only ends when the client goes away
+	counter := &countingReader{reader: io.MultiReader(bytes.NewReader(head), body)}
+	if err := h.blobStore.Put(c.Request.Context(), asset.Key, counter, c.Request.ContentLength, contentType); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store video"})
+		return
+	}
+	asset.Size = counter.count
+
+	ctx, cancel = context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"videos." + name: asset}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		h.deleteBlob(ctx, asset.Key)
+		utils.HandleError(c, err)
+		return
+	}
+
+	previous, replaced := before.Videos[name]
+	if replaced {
+		h.deleteBlob(ctx, previous.Key)
+	}
+
+	status := http.StatusCreated
+	if replaced {
+		status = http.StatusOK
+	}
+	h.respondVideoChange(ctx, c, before, status)
+}
+
+// DeleteVideo godoc
+// @Summary      Delete movie video
+// @Description  Remove a video asset from a movie and delete its file (Admin only)
+// @Tags         Movies
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        asset path string true "Asset name"
+// @Param        If-Match header string false "ETag from GET /movies/{id}; required when MOVIE_REQUIRE_IF_MATCH is set"
+// @Success      200 {object} models.Movie "Video asset removed"
+// @Header       200 {string} ETag "New movie version tag"
+// @Failure      400 {object} ErrorResponse "Invalid ID or asset name"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Movie or video not found"
+// @Failure      412 {object} ErrorResponse "Movie was modified since it was read"
+// @Failure      428 {object} ErrorResponse "If-Match header required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/videos/{asset} [delete]
+func (h *MovieHandler) DeleteVideo(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(repositories.WithoutMaturityLimit(context.Background()), 5*time.Second)
+	defer cancel()
+
+	before, name, ok := h.findVideoMovie(ctx, c, "asset")
+	if !ok {
+		return
+	}
+
+	asset, exists := before.Videos[name]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+
+	conditional, ok := h.checkIfMatch(c, before)
+	if !ok {
+		return
+	}
+
+	var err error
+	id := before.ID.Hex()
+	update := bson.M{"$unset": bson.M{"videos." + name: ""}}
+	if conditional {
+		err = h.movieRepo.UpdateVersioned(ctx, id, before.Version, update)
+	} else {
+		err = h.movieRepo.Update(ctx, id, update)
+	}
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	h.deleteBlob(ctx, asset.Key)
+	h.respondVideoChange(ctx, c, before, http.StatusOK)
+}
+
+// findVideoMovie loads the movie and validates the asset or rendition name in
+// the path parameter, writing the error response itself when ok is false
+func (h *MovieHandler) findVideoMovie(ctx context.Context, c *gin.Context, param string) (*models.Movie, string, bool) {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return nil, "", false
+	}
+
+	name := c.Param(param)
+	if !videoAssetName.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " name. Use up to 64 lowercase letters, digits, - and _"})
+		return nil, "", false
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return nil, "", false
+	}
+
+	return movie, name, true
+}
+
+// respondVideoChange records the revision of a video write and responds with the stored movie
+func (h *MovieHandler) respondVideoChange(ctx context.Context, c *gin.Context, before *models.Movie, status int) {
+	updatedMovie, err := h.movieRepo.FindByID(ctx, before.ID.Hex())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated movie"})
+		return
+	}
+
+	actor, _ := middleware.GetUserID(c)
+	h.recordRevision(ctx, models.RevisionActionUpdate, actor, before, updatedMovie)
+
+	c.Header("ETag", utils.MovieETag(updatedMovie.Version))
+	c.JSON(status, updatedMovie)
+}
+
+// deleteBlob removes a file that is no longer referenced. The movie is already
+// written at this point, so a failure only leaves an orphaned file behind.
+func (h *MovieHandler) deleteBlob(ctx context.Context, key string) {
+	if err := h.blobStore.Delete(ctx, key); err != nil {
+		log.Printf("failed to delete blob %s: %v", key, err)
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,227 @@
+package routes
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ParentalControlsHandler manages the parental PIN of the signed-in account
+type ParentalControlsHandler struct {
+	tokenService *authservice.TokenService
+	cfg          *config.Config
+	userRepo     repositories.UserRepository
+}
+
+// NewParentalControlsHandler creates a new parental controls handler with dependencies injected
+func NewParentalControlsHandler(ts *authservice.TokenService, cfg *config.Config, userRepo repositories.UserRepository) *ParentalControlsHandler {
+	return &ParentalControlsHandler{
+		tokenService: ts,
+		cfg:          cfg,
+		userRepo:     userRepo,
+	}
+}
+
+// Get godoc
+// @Summary      Get my parental controls
+// @Description  Whether a parental PIN is set, and until when PIN checks are refused after too many wrong PINs
+// @Tags         Parental Controls
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.ParentalControlsResponse "Parental controls"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/parental-controls [get]
+func (h *ParentalControlsHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.Personalized(c)
+	c.JSON(http.StatusOK, models.NewParentalControlsResponse(user.ParentalControls))
+}
+
+// Put godoc
+// @Summary      Set my parental PIN
+// @Description  The first call sets the PIN to pin; later calls must send the current PIN as pin and may change it with new_pin. Once set, the PIN is needed to create or delete profiles, to change a profile's maturity limit or lock, and to select a locked profile. After PARENTAL_PIN_MAX_ATTEMPTS wrong PINs, PIN checks are refused for PARENTAL_PIN_LOCKOUT_MINUTES.
+// @Tags         Parental Controls
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        controls body models.ParentalControlsRequest true "Current PIN, or the new one on first use, and the PIN to change to"
+// @Success      200 {object} models.ParentalControlsResponse "Updated parental controls"
+// @Failure      400 {object} ErrorResponse "Invalid request data"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Incorrect PIN"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Header       429 {string} Retry-After "Seconds until the PIN can be tried again"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/parental-controls [put]
+func (h *ParentalControlsHandler) Put(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.ParentalControlsRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Without controls yet, pin becomes the PIN
+	controls := user.ParentalControls
+	newPIN := req.NewPIN
+	if controls == nil {
+		controls = &models.ParentalControls{}
+		newPIN = req.PIN
+	} else if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+		return
+	}
+
+	if newPIN != "" {
+		hash, err := hashPassword(newPIN)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set PIN"})
+			return
+		}
+		controls.PINHash = hash
+	}
+	controls.FailedAttempts = 0
+	controls.LockedUntil = nil
+	controls.UpdatedAt = time.Now()
+
+	if err := h.userRepo.SetParentalControls(ctx, userID, controls); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewParentalControlsResponse(controls))
+}
+
+// Delete godoc
+// @Summary      Remove my parental PIN
+// @Description  Remove the PIN. Takes the current PIN. Profile maturity limits and locks stay, but anyone signed in can change them again.
+// @Tags         Parental Controls
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        pin body models.ParentalPINRequest true "Current PIN"
+// @Success      204 "Parental PIN removed"
+// @Failure      400 {object} ErrorResponse "Invalid request data"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Incorrect PIN"
+// @Failure      404 {object} ErrorResponse "No parental PIN is set"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Header       429 {string} Retry-After "Seconds until the PIN can be tried again"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/parental-controls [delete]
+func (h *ParentalControlsHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.ParentalPINRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if user.ParentalControls == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No parental PIN is set"})
+		return
+	}
+	if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+		return
+	}
+
+	if err := h.userRepo.SetParentalControls(ctx, userID, nil); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// checkParentalPIN compares pin with the account's parental PIN, counting wrong
+// ones towards the lockout and starting the count over on a correct one.
+// Accounts without a PIN pass. It writes the error
+// response itself when it returns false.
+func checkParentalPIN(ctx context.Context, c *gin.Context, cfg *config.Config, userRepo repositories.UserRepository, user *models.User, pin string) bool {
+	controls := user.ParentalControls
+	if controls == nil {
+		return true
+	}
+
+	if controls.Locked(time.Now()) {
+		pinLocked(c, *controls.LockedUntil)
+		return false
+	}
+
+	if pin == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Parental PIN required"})
+		return false
+	}
+
+	if verifyPassword(controls.PINHash, pin) {
+		updated, err := userRepo.RecordPINSuccess(ctx, user.UserID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return false
+		}
+		// A parallel wrong guess may have locked PIN checks since user was read
+		if updated != nil && updated.Locked(time.Now()) {
+			pinLocked(c, *updated.LockedUntil)
+			return false
+		}
+		return true
+	}
+
+	lockout := time.Duration(cfg.ParentalPINLockoutMin) * time.Minute
+	updated, err := userRepo.RecordPINFailure(ctx, user.UserID, cfg.ParentalPINMaxAttempts, lockout)
+	if err != nil {
+		utils.HandleError(c, err)
+		return false
+	}
+
+	if updated.Locked(time.Now()) {
+		pinLocked(c, *updated.LockedUntil)
+		return false
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Incorrect PIN"})
+	return false
+}
+
+func pinLocked(c *gin.Context, until time.Time) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(until).Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many incorrect PINs, try again later"})
+}
@@ -0,0 +1,362 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PersonHandler handles people and filmography requests
+type PersonHandler struct {
+	tokenService *authservice.TokenService
+	personRepo   repositories.PersonRepository
+	movieRepo    repositories.MovieRepository
+}
+
+// NewPersonHandler creates a new person handler with dependencies injected
+func NewPersonHandler(ts *authservice.TokenService, personRepo repositories.PersonRepository, movieRepo repositories.MovieRepository) *PersonHandler {
+	return &PersonHandler{
+		tokenService: ts,
+		personRepo:   personRepo,
+		movieRepo:    movieRepo,
+	}
+}
+
+// PersonListResponse for Swagger documentation
+type PersonListResponse struct {
+	Data       []models.Person `json:"data"`
+	Pagination PaginationInfo  `json:"pagination"`
+}
+
+// GetAll godoc
+// @Summary      List people
+// @Description  Retrieve cast and crew members, optionally searching by name
+// @Tags         People
+// @Produce      json
+// @Param        q query string false "Search by name"
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} PersonListResponse "List of people with pagination info"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people [get]
+func (h *PersonHandler) GetAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	people, total, err := h.personRepo.FindAll(ctx, utils.SanitizeString(c.Query("q")), pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       people,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// GetByID godoc
+// @Summary      Get person by ID
+// @Description  Retrieve a single cast or crew member
+// @Tags         People
+// @Produce      json
+// @Param        id path string true "Person ID"
+// @Success      200 {object} models.Person "Person details"
+// @Failure      404 {object} ErrorResponse "Person not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people/{id} [get]
+func (h *PersonHandler) GetByID(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	person, err := h.personRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// GetMovies godoc
+// @Summary      Get a person's filmography
+// @Description  Retrieve the movies a person is credited on, newest release first
+// @Tags         People
+// @Produce      json
+// @Param        id path string true "Person ID"
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} MovieListResponse "Movies crediting the person"
+// @Failure      404 {object} ErrorResponse "Person not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people/{id}/movies [get]
+func (h *PersonHandler) GetMovies(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	person, err := h.personRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	movies, total, err := h.movieRepo.FindByPerson(ctx, person.ID, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       movies,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// Create godoc
+// @Summary      Create person
+// @Description  Add a cast or crew member (Admin only)
+// @Tags         People
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        person body models.PersonRequest true "Person data"
+// @Success      201 {object} models.Person "Person created"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people [post]
+func (h *PersonHandler) Create(c *gin.Context) {
+	var req models.PersonRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	person := req.ToPerson()
+	if err := h.personRepo.Create(ctx, &person); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// Update godoc
+// @Summary      Update person
+// @Description  Replace a person's details and refresh their name on movie credits (Admin only)
+// @Tags         People
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Person ID"
+// @Param        person body models.PersonRequest true "Person data"
+// @Success      200 {object} models.Person "Person updated"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Person not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people/{id} [put]
+func (h *PersonHandler) Update(c *gin.Context) {
+	var req models.PersonRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	update := bson.M{
+		"name":                 req.Name,
+		"biography":            req.Biography,
+		"photo_url":            req.PhotoURL,
+		"known_for_department": req.KnownForDepartment,
+	}
+	if err := h.personRepo.Update(ctx, id, update); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	person, err := h.personRepo.FindByID(ctx, id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.movieRepo.RenameCreditPerson(ctx, person.ID, person.Name); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// Delete godoc
+// @Summary      Delete person
+// @Description  Delete a person who is not credited on any movie (Admin only)
+// @Tags         People
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Person ID"
+// @Success      200 {object} MessageResponse "Person deleted"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Person not found"
+// @Failure      409 {object} ErrorResponse "Person is still credited on movies"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people/{id} [delete]
+func (h *PersonHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	person, err := h.personRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Trashed movies count too, otherwise restoring them would leave dangling credits
+	credited, err := h.movieRepo.IsPersonCredited(ctx, person.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if credited {
+		utils.HandleError(c, repositories.ErrPersonInUse)
+		return
+	}
+
+	if err := h.personRepo.Delete(ctx, person.ID.Hex()); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Person deleted successfully"})
+}
+
+// Merge godoc
+// @Summary      Merge duplicate people
+// @Description  Move every credit of the source people onto the target person, then delete the sources. A movie crediting more than one of them for the same job and character keeps one credit. A merge that fails before the sources are deleted can be sent again (Admin only)
+// @Tags         People
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Target person ID"
+// @Param        request body models.PersonMergeRequest true "Duplicate person IDs"
+// @Success      200 {object} models.Person "Merged person"
+// @Failure      400 {object} ErrorResponse "Invalid request body or repeated source ID"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Person not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /people/{id}/merge [post]
+func (h *PersonHandler) Merge(c *gin.Context) {
+	var req models.PersonMergeRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	target, err := h.personRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	sourceIDs := make([]bson.ObjectID, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil || objectID == target.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid source person ID: " + id})
+			return
+		}
+		sourceIDs = append(sourceIDs, objectID)
+	}
+
+	sources, err := h.personRepo.FindByIDs(ctx, sourceIDs)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if len(sources) != len(sourceIDs) {
+		utils.HandleError(c, repositories.ErrPersonNotFound)
+		return
+	}
+
+	// There is no transaction across the two collections. Every step can be
+	// repeated, and the sources are only deleted once no movie credits them,
+	// so a merge that fails before the delete is finished by sending it again.
+	if err := h.movieRepo.ReassignCredits(ctx, sourceIDs, target); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.personRepo.DeleteMany(ctx, sourceIDs); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// A movie write that resolved its credits before the delete may have
+	// credited a source since; later writes reject the deleted people
+	if err := h.movieRepo.ReassignCredits(ctx, sourceIDs, target); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+// resolveCredits checks that every credited person exists and fills in their current name
+func resolveCredits(ctx context.Context, personRepo repositories.PersonRepository, credits []models.Credit) ([]models.Credit, error) {
+	if len(credits) == 0 {
+		return credits, nil
+	}
+
+	ids := make([]bson.ObjectID, 0, len(credits))
+	for _, credit := range credits {
+		ids = append(ids, credit.PersonID)
+	}
+
+	people, err := personRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[bson.ObjectID]string, len(people))
+	for _, person := range people {
+		names[person.ID] = person.Name
+	}
+
+	resolved := make([]models.Credit, len(credits))
+	for i, credit := range credits {
+		name, ok := names[credit.PersonID]
+		if !ok {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Unknown person in credits", credit.PersonID.Hex())
+		}
+		credit.Name = name
+		resolved[i] = credit
+	}
+
+	return resolved, nil
+}
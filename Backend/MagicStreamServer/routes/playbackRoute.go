@@ -0,0 +1,357 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	playbackservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/playback"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PlaybackHandler starts playback sessions with signed streaming URLs and
+// enforces how many streams an account may play at once
+type PlaybackHandler struct {
+	tokenService *authservice.TokenService
+	cfg          *config.Config
+	signer       *playbackservice.URLSigner
+	movieRepo    repositories.MovieRepository
+	userRepo     repositories.UserRepository
+	sessionRepo  repositories.PlaybackSessionRepository
+}
+
+// NewPlaybackHandler creates a new playback handler with dependencies injected
+func NewPlaybackHandler(ts *authservice.TokenService, cfg *config.Config, signer *playbackservice.URLSigner, movieRepo repositories.MovieRepository, userRepo repositories.UserRepository, sessionRepo repositories.PlaybackSessionRepository) *PlaybackHandler {
+	return &PlaybackHandler{
+		tokenService: ts,
+		cfg:          cfg,
+		signer:       signer,
+		movieRepo:    movieRepo,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+	}
+}
+
+// StreamLimitResponse for Swagger documentation
+type StreamLimitResponse struct {
+	Error          string                 `json:"error" example:"Too many streams playing at once"`
+	Limit          int                    `json:"limit" example:"2"`
+	ActiveSessions []models.StreamSession `json:"active_sessions"`
+}
+
+// StreamSessionsResponse for Swagger documentation
+type StreamSessionsResponse struct {
+	Data  []models.StreamSession `json:"data"`
+	Limit int                    `json:"limit" example:"2"`
+}
+
+// Start godoc
+// @Summary      Start playback
+// @Description  Start a playback session for a movie the caller's plan includes. Returns signed URLs for the HLS master playlist and each video asset that work without an Authorization header, so they can be handed straight to a player. Each URL is scoped to the caller, the movie and one asset, and expires after PLAYBACK_URL_TTL_MINUTES. The HLS playlists only list the renditions the plan's quality allows, and videos above it get no URL. The session counts against the plan's concurrent stream limit until it is stopped or its heartbeats stop; past the limit the response lists the active sessions, and the request can be repeated with replace_session_id to stop one of them.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        playback body models.PlaybackStartRequest false "Device name and session to replace"
+// @Success      201 {object} models.PlaybackSession "Playback session"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format or request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie, or every quality it is available in, not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie not found or has nothing to play"
+// @Failure      409 {object} StreamLimitResponse "Concurrent stream limit reached"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/playback [post]
+func (h *PlaybackHandler) Start(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	// The body is optional
+	var req models.PlaybackStartRequest
+	if c.Request.ContentLength != 0 && !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if len(movie.Renditions) == 0 && len(movie.Videos) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie has nothing to play"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	plan := user.EffectivePlan(now)
+	if !checkEntitlement(c, plan, movie) {
+		return
+	}
+
+	// Only the renditions and videos the plan's quality allows are signed
+	maxQuality := planFeatures(h.cfg, plan).MaxQuality
+	playableHLS := false
+	for _, rendition := range movie.Renditions {
+		if withinQuality(rendition.Height, maxQuality) {
+			playableHLS = true
+			break
+		}
+	}
+	videos := make(map[string]models.VideoAsset, len(movie.Videos))
+	for name, asset := range movie.Videos {
+		if withinQuality(asset.Height, maxQuality) {
+			videos[name] = asset
+		}
+	}
+	if !playableHLS && len(videos) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your plan does not include the qualities this movie is available in"})
+		return
+	}
+
+	if req.ReplaceSessionID != "" {
+		replaceID, _ := bson.ObjectIDFromHex(req.ReplaceSessionID)
+		// A session that already ended has freed its slot anyway
+		if err := h.sessionRepo.Delete(ctx, replaceID, userID); err != nil && !errors.Is(err, repositories.ErrPlaybackSessionNotFound) {
+			utils.HandleError(c, err)
+			return
+		}
+	}
+
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	stream := &models.StreamSession{
+		ID:              bson.NewObjectID(),
+		UserID:          userID,
+		MovieID:         movie.ID,
+		MovieTitle:      movie.Title,
+		Device:          req.Device,
+		UserAgent:       userAgent,
+		StartedAt:       now,
+		LastHeartbeatAt: now,
+		ExpiresAt:       now.Add(h.sessionTimeout()),
+	}
+	if err := h.sessionRepo.Create(ctx, stream); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// The session is inserted before counting, so of two devices starting at
+	// once only the later one is turned away
+	if limit := h.streamLimit(user, plan); limit > 0 {
+		active, err := h.sessionRepo.FindActiveByUser(ctx, userID)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		others := make([]models.StreamSession, 0, len(active))
+		position := 0
+		for i, session := range active {
+			if session.ID == stream.ID {
+				position = i
+			} else {
+				others = append(others, session)
+			}
+		}
+
+		if position >= limit {
+			if err := h.sessionRepo.Delete(ctx, stream.ID, userID); err != nil {
+				log.Println("Failed to remove rejected playback session:", err)
+			}
+			c.JSON(http.StatusConflict, StreamLimitResponse{
+				Error:          "Too many streams playing at once. Stop one of the active sessions, or start again with its ID as replace_session_id",
+				Limit:          limit,
+				ActiveSessions: others,
+			})
+			return
+		}
+	}
+
+	session := models.PlaybackSession{
+		SessionID:         stream.ID.Hex(),
+		MovieID:           movie.ID.Hex(),
+		ExpiresAt:         now.Add(h.signer.TTL()).UTC().Truncate(time.Second),
+		HeartbeatInterval: int(h.sessionTimeout() / time.Second / 3),
+	}
+	claims := playbackservice.PlaybackClaims{
+		SessionID:  session.SessionID,
+		UserID:     userID,
+		MovieID:    session.MovieID,
+		MaxQuality: maxQuality,
+	}
+
+	if playableHLS {
+		claims.Scope = playbackservice.ScopeHLS
+		token, err := h.signer.Sign(claims, session.ExpiresAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign playback URL"})
+			return
+		}
+		session.HLSURL = h.playURL(token, "hls", session.MovieID, "master.m3u8")
+	}
+
+	if len(videos) > 0 {
+		session.Videos = make(map[string]string, len(videos))
+		for name, asset := range videos {
+			claims.Scope = playbackservice.VideoScope(name)
+			claims.Object = asset.Key
+			token, err := h.signer.Sign(claims, session.ExpiresAt)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign playback URL"})
+				return
+			}
+			session.Videos[name] = h.playURL(token, "stream", session.MovieID, name)
+		}
+	}
+
+	utils.Personalized(c)
+	c.JSON(http.StatusCreated, session)
+}
+
+// Heartbeat godoc
+// @Summary      Playback heartbeat
+// @Description  Keep a playback session alive. Players should call this every heartbeat_interval_seconds while playing; a session without heartbeats for PLAYBACK_SESSION_TIMEOUT_SECONDS ends and frees its stream slot. A 404 means the session ended or was stopped from another device, and the player should stop.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      json
+// @Param        session_id path string true "Playback session ID"
+// @Success      200 {object} models.StreamSession "Session extended"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Playback session not found or has ended"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/playback-sessions/{session_id}/heartbeat [post]
+func (h *PlaybackHandler) Heartbeat(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sessionID, err := bson.ObjectIDFromHex(c.Param("session_id"))
+	if err != nil {
+		utils.HandleError(c, repositories.ErrPlaybackSessionNotFound)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	session, err := h.sessionRepo.Heartbeat(ctx, sessionID, userID, time.Now().Add(h.sessionTimeout()))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// ListSessions godoc
+// @Summary      List my active streams
+// @Description  Retrieve the caller's playback sessions that are still alive, oldest first, with the concurrent stream limit of their plan (0 means unlimited)
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} StreamSessionsResponse "Active playback sessions"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/playback-sessions [get]
+func (h *PlaybackHandler) ListSessions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	sessions, err := h.sessionRepo.FindActiveByUser(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.Personalized(c)
+	c.JSON(http.StatusOK, StreamSessionsResponse{Data: sessions, Limit: h.streamLimit(user, user.EffectivePlan(time.Now()))})
+}
+
+// StopSession godoc
+// @Summary      Stop a stream
+// @Description  End one of the caller's playback sessions, for example to free a slot used by another device. That device learns of it on its next heartbeat.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      json
+// @Param        session_id path string true "Playback session ID"
+// @Success      200 {object} MessageResponse "Playback session stopped"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Playback session not found or has ended"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/playback-sessions/{session_id} [delete]
+func (h *PlaybackHandler) StopSession(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sessionID, err := bson.ObjectIDFromHex(c.Param("session_id"))
+	if err != nil {
+		utils.HandleError(c, repositories.ErrPlaybackSessionNotFound)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if err := h.sessionRepo.Delete(ctx, sessionID, userID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Playback session stopped"})
+}
+
+// streamLimit returns how many streams the user may play at once, 0 meaning
+// no limit. A limit set for the user's role overrides the plan's.
+func (h *PlaybackHandler) streamLimit(user *models.User, plan string) int {
+	if limit, ok := h.cfg.StreamLimitsByRole[user.Role]; ok {
+		return limit
+	}
+	return planFeatures(h.cfg, plan).MaxStreams
+}
+
+func (h *PlaybackHandler) sessionTimeout() time.Duration {
+	return time.Duration(h.cfg.PlaybackSessionTimeoutSec) * time.Second
+}
+
+// playURL builds an absolute signed URL, since players resolve it outside the API client
+func (h *PlaybackHandler) playURL(token string, parts ...string) string {
+	base := strings.TrimSuffix(h.cfg.BackendServerURI, "/")
+	if base == "" {
+		base = "http://localhost:" + h.cfg.Port
+	}
+	return base + "/api/v1/play/" + token + "/" + strings.Join(parts, "/")
+}
@@ -0,0 +1,373 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ProfileHandler manages the viewer profiles of the signed-in account
+type ProfileHandler struct {
+	tokenService  *authservice.TokenService
+	cfg           *config.Config
+	userRepo      repositories.UserRepository
+	genreRepo     repositories.GenreRepository
+	watchlistRepo repositories.WatchlistRepository
+	historyRepo   repositories.WatchHistoryRepository
+}
+
+// NewProfileHandler creates a new profile handler with dependencies injected
+func NewProfileHandler(ts *authservice.TokenService, cfg *config.Config, userRepo repositories.UserRepository, genreRepo repositories.GenreRepository, watchlistRepo repositories.WatchlistRepository, historyRepo repositories.WatchHistoryRepository) *ProfileHandler {
+	return &ProfileHandler{
+		tokenService:  ts,
+		cfg:           cfg,
+		userRepo:      userRepo,
+		genreRepo:     genreRepo,
+		watchlistRepo: watchlistRepo,
+		historyRepo:   historyRepo,
+	}
+}
+
+// ProfileListResponse for Swagger documentation
+type ProfileListResponse struct {
+	Data            []models.Profile `json:"data"`
+	ActiveProfileID string           `json:"active_profile_id" example:"6650f1c2e4b0a1b2c3d4e5f6"`
+	Limit           int              `json:"limit" example:"5"`
+}
+
+// ProfileSelectResponse carries the tokens for the selected profile
+type ProfileSelectResponse struct {
+	AccessToken  string         `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string         `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Profile      models.Profile `json:"profile"`
+}
+
+// GetAll godoc
+// @Summary      List my profiles
+// @Description  The profiles of the account, the default one first, with the one the caller is signed in as
+// @Tags         Profiles
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} ProfileListResponse "Profiles"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/profiles [get]
+func (h *ProfileHandler) GetAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+	profileID, _ := middleware.GetProfileID(c)
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.Personalized(c)
+	c.JSON(http.StatusOK, ProfileListResponse{
+		Data:            user.Profiles,
+		ActiveProfileID: profileID.Hex(),
+		Limit:           h.cfg.MaxProfiles,
+	})
+}
+
+// Create godoc
+// @Summary      Create a profile
+// @Description  Add a viewer profile, up to MAX_PROFILES per account. Takes the parental PIN once one is set; locking a profile needs a PIN to be set first.
+// @Tags         Profiles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        profile body models.ProfileRequest true "Profile"
+// @Success      201 {object} models.Profile "Created profile"
+// @Failure      400 {object} ErrorResponse "Invalid request data"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Parental PIN required or incorrect"
+// @Failure      409 {object} ErrorResponse "Profile limit reached"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/profiles [post]
+func (h *ProfileHandler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.ProfileRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if h.cfg.MaxProfiles > 0 && len(user.Profiles) >= h.cfg.MaxProfiles {
+		utils.HandleError(c, repositories.ErrProfileLimitReached)
+		return
+	}
+	if req.Locked && user.ParentalControls == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set a parental PIN before locking a profile"})
+		return
+	}
+	if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+		return
+	}
+
+	genres, err := h.favouriteGenres(ctx, req.FavouriteGenres)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	profile := models.Profile{
+		ID:               bson.NewObjectID(),
+		Name:             req.Name,
+		Avatar:           req.Avatar,
+		FavouriteGenres:  genres,
+		MaxCertification: req.MaxCertification,
+		Locked:           req.Locked,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := h.userRepo.AddProfile(ctx, userID, &profile, h.cfg.MaxProfiles); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// Update godoc
+// @Summary      Update a profile
+// @Description  Replace a profile's name, avatar, favourite genres, maturity limit and lock. Changing the maturity limit or the lock takes the parental PIN once one is set.
+// @Tags         Profiles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        profile_id path string true "Profile ID"
+// @Param        profile body models.ProfileRequest true "Profile"
+// @Success      200 {object} models.Profile "Updated profile"
+// @Failure      400 {object} ErrorResponse "Invalid request data"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Parental PIN required or incorrect"
+// @Failure      404 {object} ErrorResponse "Profile not found"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/profiles/{profile_id} [put]
+func (h *ProfileHandler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	profileID, err := bson.ObjectIDFromHex(c.Param("profile_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID format"})
+		return
+	}
+
+	var req models.ProfileRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	current := user.Profile(profileID)
+	if current == nil {
+		utils.HandleError(c, repositories.ErrProfileNotFound)
+		return
+	}
+
+	if req.Locked && !current.Locked && user.ParentalControls == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set a parental PIN before locking a profile"})
+		return
+	}
+	if req.MaxCertification != current.MaxCertification || req.Locked != current.Locked {
+		if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+			return
+		}
+	}
+
+	genres, err := h.favouriteGenres(ctx, req.FavouriteGenres)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	profile := *current
+	profile.Name = req.Name
+	profile.Avatar = req.Avatar
+	profile.FavouriteGenres = genres
+	profile.MaxCertification = req.MaxCertification
+	profile.Locked = req.Locked
+	profile.UpdatedAt = time.Now()
+
+	if err := h.userRepo.ReplaceProfile(ctx, userID, &profile); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// Delete godoc
+// @Summary      Delete a profile
+// @Description  Delete a profile with its watchlist and watch history, and sign it out everywhere. Takes the parental PIN once one is set. The last profile can't be deleted; deleting the default one makes the next profile the default.
+// @Tags         Profiles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        profile_id path string true "Profile ID"
+// @Param        pin body models.ProfileSelectRequest false "Parental PIN"
+// @Success      204 "Profile deleted"
+// @Failure      400 {object} ErrorResponse "Invalid profile ID"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Parental PIN required or incorrect"
+// @Failure      404 {object} ErrorResponse "Profile not found"
+// @Failure      409 {object} ErrorResponse "Last profile"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/profiles/{profile_id} [delete]
+func (h *ProfileHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	profileID, err := bson.ObjectIDFromHex(c.Param("profile_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID format"})
+		return
+	}
+
+	var req models.ProfileSelectRequest
+	if c.Request.ContentLength != 0 && !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if user.Profile(profileID) == nil {
+		utils.HandleError(c, repositories.ErrProfileNotFound)
+		return
+	}
+	if !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+		return
+	}
+
+	if err := h.userRepo.DeleteProfile(ctx, userID, profileID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// The profile is gone either way; leftovers only take up space
+	if err := h.watchlistRepo.DeleteByProfile(ctx, profileID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if err := h.historyRepo.DeleteByProfile(ctx, profileID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if err := h.tokenService.RevokeProfileTokens(userID, profileID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Select godoc
+// @Summary      Switch profile
+// @Description  Issue tokens for another profile of the account. Locked profiles take the parental PIN.
+// @Tags         Profiles
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        profile_id path string true "Profile ID"
+// @Param        pin body models.ProfileSelectRequest false "Parental PIN, for locked profiles"
+// @Success      200 {object} ProfileSelectResponse "Tokens for the profile"
+// @Failure      400 {object} ErrorResponse "Invalid request data"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Parental PIN required or incorrect"
+// @Failure      404 {object} ErrorResponse "Profile not found"
+// @Failure      429 {object} ErrorResponse "Too many incorrect PINs"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/profiles/{profile_id}/select [post]
+func (h *ProfileHandler) Select(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	profileID, err := bson.ObjectIDFromHex(c.Param("profile_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID format"})
+		return
+	}
+
+	var req models.ProfileSelectRequest
+	if c.Request.ContentLength != 0 && !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	profile := user.Profile(profileID)
+	if profile == nil {
+		utils.HandleError(c, repositories.ErrProfileNotFound)
+		return
+	}
+	if profile.Locked && !checkParentalPIN(ctx, c, h.cfg, h.userRepo, user, req.PIN) {
+		return
+	}
+
+	tokenPair, err := h.tokenService.GenerateTokenPair(userID, profile.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProfileSelectResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		Profile:      *profile,
+	})
+}
+
+// favouriteGenres resolves the genres of a profile request against the catalog
+func (h *ProfileHandler) favouriteGenres(ctx context.Context, genres []models.Genre) ([]models.Genre, error) {
+	if len(genres) == 0 {
+		return []models.Genre{}, nil
+	}
+	return resolveGenres(ctx, h.genreRepo, genres)
+}
@@ -0,0 +1,242 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RankingHandler handles the rankings catalog
+type RankingHandler struct {
+	tokenService *authservice.TokenService
+	rankingRepo  repositories.RankingRepository
+	movieRepo    repositories.MovieRepository
+}
+
+// NewRankingHandler creates a new ranking handler with dependencies injected
+func NewRankingHandler(ts *authservice.TokenService, rankingRepo repositories.RankingRepository, movieRepo repositories.MovieRepository) *RankingHandler {
+	return &RankingHandler{
+		tokenService: ts,
+		rankingRepo:  rankingRepo,
+		movieRepo:    movieRepo,
+	}
+}
+
+// GetAll godoc
+// @Summary      List rankings
+// @Description  Retrieve the rankings catalog, best first
+// @Tags         Rankings
+// @Produce      json
+// @Success      200 {array} models.Ranking "Rankings in better-than order"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /rankings [get]
+func (h *RankingHandler) GetAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rankings, err := h.rankingRepo.FindAll(ctx)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rankings)
+}
+
+// GetByValue godoc
+// @Summary      Get ranking by value
+// @Description  Retrieve a single ranking level
+// @Tags         Rankings
+// @Produce      json
+// @Param        value path int true "Ranking value"
+// @Success      200 {object} models.Ranking "Ranking details"
+// @Failure      400 {object} ErrorResponse "Invalid ranking value"
+// @Failure      404 {object} ErrorResponse "Ranking not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /rankings/{value} [get]
+func (h *RankingHandler) GetByValue(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := strconv.Atoi(c.Param("value"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ranking value"})
+		return
+	}
+
+	ranking, err := h.rankingRepo.FindByValue(ctx, value)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// Create godoc
+// @Summary      Create ranking
+// @Description  Add a ranking level to the catalog; order places it in the better-than order, lower is better (Admin only)
+// @Tags         Rankings
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        ranking body models.RankingRequest true "Ranking data"
+// @Success      201 {object} models.Ranking "Ranking created"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      409 {object} ErrorResponse "Ranking value already exists"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /rankings [post]
+func (h *RankingHandler) Create(c *gin.Context) {
+	var req models.RankingRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ranking := req.ToRanking()
+	if err := h.rankingRepo.Create(ctx, &ranking); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ranking)
+}
+
+// Update godoc
+// @Summary      Update ranking
+// @Description  Rename or reorder a ranking level and refresh it on every movie given that ranking (Admin only)
+// @Tags         Rankings
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        value path int true "Ranking value"
+// @Param        ranking body models.RankingUpdateRequest true "Ranking data"
+// @Success      200 {object} models.Ranking "Ranking updated"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Ranking not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /rankings/{value} [put]
+func (h *RankingHandler) Update(c *gin.Context) {
+	value, err := strconv.Atoi(c.Param("value"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ranking value"})
+		return
+	}
+
+	var req models.RankingUpdateRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.rankingRepo.Update(ctx, value, req.RankingName, req.Order); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	ranking, err := h.rankingRepo.FindByValue(ctx, value)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.movieRepo.SyncRanking(ctx, ranking); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ranking)
+}
+
+// Delete godoc
+// @Summary      Delete ranking
+// @Description  Remove a ranking level that no movie is given (Admin only)
+// @Tags         Rankings
+// @Security     BearerAuth
+// @Produce      json
+// @Param        value path int true "Ranking value"
+// @Success      200 {object} MessageResponse "Ranking deleted"
+// @Failure      400 {object} ErrorResponse "Invalid ranking value"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} ErrorResponse "Admin access required"
+// @Failure      404 {object} ErrorResponse "Ranking not found"
+// @Failure      409 {object} ErrorResponse "Ranking is still assigned to movies"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /rankings/{value} [delete]
+func (h *RankingHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	value, err := strconv.Atoi(c.Param("value"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ranking value"})
+		return
+	}
+
+	// Trashed movies count too, otherwise restoring them would bring back an unknown ranking
+	assigned, err := h.movieRepo.IsRankingAssigned(ctx, value)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if assigned {
+		utils.HandleError(c, repositories.ErrRankingInUse)
+		return
+	}
+
+	if err := h.rankingRepo.Delete(ctx, value); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ranking deleted successfully"})
+}
+
+// resolveRanking fills in the catalog name and order of a movie's ranking
+func resolveRanking(ctx context.Context, rankingRepo repositories.RankingRepository, ranking models.Ranking) (models.Ranking, error) {
+	resolved, err := rankingRepo.FindByValue(ctx, ranking.RankingValue)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRankingNotFound) {
+			return ranking, utils.NewAppError(http.StatusBadRequest, "Unknown ranking", strconv.Itoa(ranking.RankingValue))
+		}
+		return ranking, err
+	}
+
+	return *resolved, nil
+}
+
+// rankingOrderFilter turns the ranking query parameter into the better-than
+// order to filter on; movies ranked at least as well as that ranking match
+func rankingOrderFilter(ctx context.Context, rankingRepo repositories.RankingRepository, query string) (int, error) {
+	if query == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(query)
+	if err != nil {
+		return 0, utils.NewAppError(http.StatusBadRequest, "Invalid ranking filter", query)
+	}
+
+	ranking, err := resolveRanking(ctx, rankingRepo, models.Ranking{RankingValue: value})
+	if err != nil {
+		return 0, err
+	}
+
+	return ranking.Order, nil
+}
@@ -0,0 +1,333 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ReviewHandler handles user ratings and reviews
+type ReviewHandler struct {
+	tokenService *authservice.TokenService
+	reviewRepo   repositories.ReviewRepository
+	movieRepo    repositories.MovieRepository
+	userRepo     repositories.UserRepository
+}
+
+// NewReviewHandler creates a new review handler with dependencies injected
+func NewReviewHandler(ts *authservice.TokenService, reviewRepo repositories.ReviewRepository, movieRepo repositories.MovieRepository, userRepo repositories.UserRepository) *ReviewHandler {
+	return &ReviewHandler{
+		tokenService: ts,
+		reviewRepo:   reviewRepo,
+		movieRepo:    movieRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// ReviewListResponse for Swagger documentation
+type ReviewListResponse struct {
+	Data       []models.Review `json:"data"`
+	Pagination PaginationInfo  `json:"pagination"`
+}
+
+// GetByMovie godoc
+// @Summary      List movie reviews
+// @Description  Retrieve user reviews of a movie, most helpful or most recent first
+// @Tags         Reviews
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        sort query string false "helpful (default) or recent"
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} ReviewListResponse "Reviews with pagination info"
+// @Failure      400 {object} ErrorResponse "Invalid sort"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/reviews [get]
+func (h *ReviewHandler) GetByMovie(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	sort := c.DefaultQuery("sort", models.ReviewSortHelpful)
+	if sort != models.ReviewSortHelpful && sort != models.ReviewSortRecent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort. Use helpful or recent"})
+		return
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+
+	reviews, total, err := h.reviewRepo.FindByMovie(ctx, movie.ID, sort, pagination.Limit, pagination.Skip)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       reviews,
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// Create godoc
+// @Summary      Review a movie
+// @Description  Rate a movie from 1 to 10 with an optional written review; each user reviews a movie once
+// @Tags         Reviews
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        review body models.ReviewRequest true "Rating and review"
+// @Success      201 {object} models.Review "Review created"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      409 {object} ErrorResponse "Movie already reviewed, use PUT"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/reviews [post]
+func (h *ReviewHandler) Create(c *gin.Context) {
+	var req models.ReviewRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	movie, err := h.movieRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	now := time.Now()
+	review := models.Review{
+		ID:        bson.NewObjectID(),
+		MovieID:   movie.ID,
+		UserID:    userID,
+		UserName:  reviewerName(user),
+		Rating:    req.Rating,
+		Title:     req.Title,
+		Body:      req.Body,
+		HelpfulBy: []string{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.reviewRepo.Create(ctx, &review); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	h.applyRatingChange(ctx, movie.ID, 0, review.Rating)
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// Update godoc
+// @Summary      Update my review
+// @Description  Replace the caller's rating and review of a movie
+// @Tags         Reviews
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        review body models.ReviewRequest true "Rating and review"
+// @Success      200 {object} models.Review "Review updated"
+// @Failure      400 {object} ErrorResponse "Invalid request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie or review not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/reviews [put]
+func (h *ReviewHandler) Update(c *gin.Context) {
+	var req models.ReviewRequest
+	if !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	review, ok := h.findOwnReview(ctx, c)
+	if !ok {
+		return
+	}
+
+	previous, err := h.reviewRepo.Update(ctx, review.ID, &req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if req.Rating != previous.Rating {
+		h.applyRatingChange(ctx, review.MovieID, previous.Rating, req.Rating)
+	}
+
+	updated, err := h.reviewRepo.FindByID(ctx, review.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// Delete godoc
+// @Summary      Delete my review
+// @Description  Remove the caller's rating and review of a movie
+// @Tags         Reviews
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Success      200 {object} MessageResponse "Review deleted"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie or review not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/reviews [delete]
+func (h *ReviewHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	review, ok := h.findOwnReview(ctx, c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.reviewRepo.Delete(ctx, review.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	h.applyRatingChange(ctx, review.MovieID, deleted.Rating, 0)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
+// MarkHelpful godoc
+// @Summary      Mark a review helpful
+// @Description  Vote a review as helpful; each user votes once per review and not on their own review
+// @Tags         Reviews
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path string true "Movie ID"
+// @Param        review_id path string true "Review ID"
+// @Success      200 {object} models.Review "Review with updated helpful count"
+// @Failure      400 {object} ErrorResponse "Own review or invalid ID"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Review not found"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /movies/{id}/reviews/{review_id}/helpful [post]
+func (h *ReviewHandler) MarkHelpful(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	reviewID, err := bson.ObjectIDFromHex(c.Param("review_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID format"})
+		return
+	}
+
+	review, err := h.reviewRepo.FindByID(ctx, reviewID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if review.MovieID.Hex() != c.Param("id") {
+		utils.HandleError(c, repositories.ErrReviewNotFound)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	if review.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot vote on your own review"})
+		return
+	}
+
+	if err := h.reviewRepo.MarkHelpful(ctx, review.ID, userID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	updated, err := h.reviewRepo.FindByID(ctx, review.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// findOwnReview loads the caller's review of the movie in the path, writing the
+// error response itself when ok is false
+func (h *ReviewHandler) findOwnReview(ctx context.Context, c *gin.Context) (*models.Review, bool) {
+	movie, err := h.movieRepo.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return nil, false
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	review, err := h.reviewRepo.FindByMovieAndUser(ctx, movie.ID, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return nil, false
+	}
+
+	return review, true
+}
+
+// applyRatingChange updates the movie's rating summary after a review write.
+// The review is already saved, so a failure doesn't fail the request. The
+// summary is recomputed from the reviews instead, so it doesn't drift.
+func (h *ReviewHandler) applyRatingChange(ctx context.Context, movieID bson.ObjectID, oldRating, newRating int) {
+	err := h.movieRepo.ApplyRatingChange(ctx, movieID, oldRating, newRating)
+	if err == nil || errors.Is(err, repositories.ErrMovieNotFound) {
+		return
+	}
+	log.Printf("failed to update rating summary of movie %s, recomputing it: %v", movieID.Hex(), err)
+
+	// The request's own deadline may be what failed
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	summary, err := h.reviewRepo.RatingSummary(ctx, movieID)
+	if err == nil {
+		err = h.movieRepo.Update(ctx, movieID.Hex(), bson.M{"$set": bson.M{"user_rating": summary}})
+	}
+	if err != nil {
+		log.Printf("failed to recompute rating summary of movie %s: %v", movieID.Hex(), err)
+	}
+}
+
+// reviewerName shows reviewers by first name and last initial
+func reviewerName(user *models.User) string {
+	name := user.FirstName
+	if last := []rune(strings.TrimSpace(user.LastName)); len(last) > 0 {
+		name += " " + strings.ToUpper(string(last[0])) + "."
+	}
+	return name
+}
@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/storage"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// StreamHandler serves movie video assets to signed-in users
+type StreamHandler struct {
+	tokenService *authservice.TokenService
+	cfg          *config.Config
+	movieRepo    repositories.MovieRepository
+	blobStore    storage.BlobStore
+}
+
+// NewStreamHandler creates a new stream handler with dependencies injected
+func NewStreamHandler(ts *authservice.TokenService, cfg *config.Config, movieRepo repositories.MovieRepository, blobStore storage.BlobStore) *StreamHandler {
+	return &StreamHandler{
+		tokenService: ts,
+		cfg:          cfg,
+		movieRepo:    movieRepo,
+		blobStore:    blobStore,
+	}
+}
+
+// Stream godoc
+// @Summary      Stream a movie video
+// @Description  Stream a video asset of a movie the caller's plan includes. Supports Range requests (206 Partial Content, multiple ranges as multipart/byteranges) and If-Range, so players can seek and resume. HEAD returns the headers only.
+// @Tags         Streaming
+// @Security     BearerAuth
+// @Produce      video/mp4
+// @Produce      video/webm
+// @Param        movie_id path string true "Movie ID"
+// @Param        asset path string true "Video asset name"
+// @Param        Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Param        If-Range header string false "ETag or Last-Modified of the asset; the range is ignored when it no longer matches"
+// @Success      200 {file} file "Whole video"
+// @Success      206 {file} file "Requested byte range"
+// @Header       200,206 {string} ETag "Asset version tag"
+// @Header       200,206 {string} Accept-Ranges "bytes"
+// @Failure      400 {object} ErrorResponse "Invalid movie ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      403 {object} EntitlementResponse "Movie or video quality not included in the caller's plan"
+// @Failure      404 {object} ErrorResponse "Movie or video not found"
+// @Failure      416 {string} string "Range not satisfiable"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /stream/{movie_id}/{asset} [get]
+func (h *StreamHandler) Stream(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	objectID, err := bson.ObjectIDFromHex(c.Param("movie_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	movie, err := h.movieRepo.FindByID(ctx, objectID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if !checkEntitlement(c, middleware.GetPlan(c), movie) {
+		return
+	}
+
+	asset, ok := movie.Videos[c.Param("asset")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+	if !withinQuality(asset.Height, planFeatures(h.cfg, middleware.GetPlan(c)).MaxQuality) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your plan does not include this quality"})
+		return
+	}
+
+	h.serveVideo(c, asset.Key, asset.ContentType)
+}
+
+// SignedStream godoc
+// @Summary      Stream a movie video with a signed URL
+// @Description  Same as GET /stream/{movie_id}/{asset}, authorized by the signed token from POST /movies/{id}/playback instead of a bearer token. The token names the file, so the movie is not loaded.
+// @Tags         Streaming
+// @Produce      video/mp4
+// @Produce      video/webm
+// @Param        token path string true "Signed playback token"
+// @Param        movie_id path string true "Movie ID"
+// @Param        asset path string true "Video asset name"
+// @Param        Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Param        If-Range header string false "ETag or Last-Modified of the asset; the range is ignored when it no longer matches"
+// @Success      200 {file} file "Whole video"
+// @Success      206 {file} file "Requested byte range"
+// @Failure      403 {object} ErrorResponse "Invalid or expired playback URL"
+// @Failure      404 {object} ErrorResponse "Video not found"
+// @Failure      416 {string} string "Range not satisfiable"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /play/{token}/stream/{movie_id}/{asset} [get]
+func (h *StreamHandler) SignedStream(c *gin.Context) {
+	claims, ok := middleware.GetPlaybackClaims(c)
+	if !ok || claims.Object == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+
+	h.serveVideo(c, claims.Object, videoContentType(claims.Object))
+}
+
+// serveVideo writes a video blob, answering Range and conditional requests
+func (h *StreamHandler) serveVideo(c *gin.Context, key, contentType string) {
+	// Reading a whole movie outlasts any fixed timeout, so the blob is read
+	// for as long as the client stays connected
+	blob, info, err := h.blobStore.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open video"})
+		return
+	}
+	defer blob.Close()
+
+	// Keys are unique per upload, so the key makes a strong validator for If-Range
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", `"`+strings.TrimSuffix(path.Base(key), path.Ext(key))+`"`)
+	c.Header("Cache-Control", "private, max-age=0, must-revalidate")
+
+	http.ServeContent(streamWriter{c.Writer}, c.Request, "", info.ModTime, blob)
+}
+
+// videoContentType returns the type of a video blob from its key's extension
+func videoContentType(key string) string {
+	for contentType, extension := range videoExtensions {
+		if path.Ext(key) == extension {
+			return contentType
+		}
+	}
+	return "application/octet-stream"
+}
+
+// streamWriter lets io.Copy reach the connection's own ReadFrom, which gin's
+// writer hides. For files on disk that turns the copy into sendfile.
+type streamWriter struct {
+	gin.ResponseWriter
+}
+
+func (w streamWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeaderNow()
+	if unwrapper, ok := w.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); ok {
+		if readerFrom, ok := unwrapper.Unwrap().(io.ReaderFrom); ok {
+			return readerFrom.ReadFrom(r)
+		}
+	}
+	return io.Copy(struct{ io.Writer }{w.ResponseWriter}, r)
+}
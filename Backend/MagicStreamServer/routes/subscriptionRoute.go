@@ -0,0 +1,353 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	billingservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/billing"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodyBytes bounds payment webhook payloads, which are a few hundred bytes
+const maxWebhookBodyBytes = 64 << 10
+
+// SubscriptionHandler lists the plans, shows the caller's subscription and
+// applies subscription changes reported by the payment provider
+type SubscriptionHandler struct {
+	tokenService     *authservice.TokenService
+	cfg              *config.Config
+	userRepo         repositories.UserRepository
+	paymentEventRepo repositories.PaymentEventRepository
+}
+
+// NewSubscriptionHandler creates a new subscription handler with dependencies injected
+func NewSubscriptionHandler(ts *authservice.TokenService, cfg *config.Config, userRepo repositories.UserRepository, paymentEventRepo repositories.PaymentEventRepository) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		tokenService:     ts,
+		cfg:              cfg,
+		userRepo:         userRepo,
+		paymentEventRepo: paymentEventRepo,
+	}
+}
+
+// PlanListResponse for Swagger documentation
+type PlanListResponse struct {
+	Data []models.Plan `json:"data"`
+}
+
+// PaymentWebhookResponse for Swagger documentation
+type PaymentWebhookResponse struct {
+	Status       string               `json:"status" example:"applied"` // applied, ignored or duplicate
+	Reason       string               `json:"reason,omitempty" example:"subscription is canceled, cannot move to past_due"`
+	Subscription *models.Subscription `json:"subscription,omitempty"`
+}
+
+// EntitlementResponse for Swagger documentation
+type EntitlementResponse struct {
+	Error        string `json:"error" example:"Upgrade to Premium to watch this movie"`
+	RequiredPlan string `json:"required_plan" example:"premium"`
+	CurrentPlan  string `json:"current_plan" example:"basic"`
+}
+
+// GetPlans godoc
+// @Summary      List plans
+// @Description  Retrieve the subscription plans from the cheapest up, with how many streams each may play at once and the highest rendition height it may watch (0 means unlimited). Each plan includes the movies of the plans before it.
+// @Tags         Subscriptions
+// @Produce      json
+// @Success      200 {object} PlanListResponse "Plans"
+// @Router       /plans [get]
+func (h *SubscriptionHandler) GetPlans(c *gin.Context) {
+	plans := make([]models.Plan, 0, len(models.PlanIDs()))
+	for _, id := range models.PlanIDs() {
+		plans = append(plans, planFeatures(h.cfg, id))
+	}
+
+	c.JSON(http.StatusOK, PlanListResponse{Data: plans})
+}
+
+// GetMine godoc
+// @Summary      Get my subscription
+// @Description  Retrieve the caller's subscription as last reported by the payment provider, and the plan they may watch now. Without a subscription, or once access_until has passed, that is the free plan.
+// @Tags         Subscriptions
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} models.SubscriptionResponse "Subscription"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/subscription [get]
+func (h *SubscriptionHandler) GetMine(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+
+	user, err := h.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.Personalized(c)
+	c.JSON(http.StatusOK, models.SubscriptionResponse{
+		Subscription: user.Subscription,
+		Plan:         planFeatures(h.cfg, user.EffectivePlan(time.Now())),
+	})
+}
+
+// PaymentWebhook godoc
+// @Summary      Payment provider webhook
+// @Description  Called by the payment provider when a subscription changes. The raw body must be signed in the X-MagicStream-Signature header as "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" with PAYMENT_WEBHOOK_SECRET>", and t must be within PAYMENT_WEBHOOK_TOLERANCE_SECONDS. Events move the subscription through trial, active, past_due and canceled, and subscription.updated changes the plan in any state; events that are redelivered, older than the last applied one, or not allowed from the current state are acknowledged with 200 without changing anything, so the provider stops retrying them.
+// @Tags         Subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        X-MagicStream-Signature header string true "Payload signature"
+// @Param        event body models.PaymentEvent true "Payment event"
+// @Success      200 {object} PaymentWebhookResponse "Event applied, ignored or already processed"
+// @Failure      400 {object} ErrorResponse "Invalid event"
+// @Failure      401 {object} ErrorResponse "Invalid or expired signature"
+// @Failure      404 {object} ErrorResponse "User not found"
+// @Failure      409 {object} ErrorResponse "Subscription changed meanwhile, or not created yet, retry"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Failure      503 {object} ErrorResponse "Payment webhooks are not configured"
+// @Router       /webhooks/payments [post]
+func (h *SubscriptionHandler) PaymentWebhook(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if h.cfg.PaymentWebhookSecret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Payment webhooks are not configured"})
+		return
+	}
+
+	// The signature covers the exact bytes sent, so it is checked before parsing
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read webhook body", "details": err.Error()})
+		return
+	}
+
+	tolerance := time.Duration(h.cfg.PaymentWebhookToleranceSec) * time.Second
+	err = billingservice.VerifySignature(h.cfg.PaymentWebhookSecret, c.GetHeader(billingservice.SignatureHeader), body, time.Now(), tolerance)
+	if err != nil {
+		message := "Invalid webhook signature"
+		if errors.Is(err, billingservice.ErrStaleSignature) {
+			message = "Webhook signature has expired"
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+		return
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	var event models.PaymentEvent
+	if !utils.ValidateRequest(c, &event) {
+		return
+	}
+
+	processed, err := h.paymentEventRepo.Exists(ctx, event.ID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	if processed {
+		c.JSON(http.StatusOK, PaymentWebhookResponse{Status: "duplicate"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(ctx, event.Data.UserID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	current := user.Subscription
+	created := time.Unix(event.Created, 0).UTC()
+
+	from := ""
+	if current != nil {
+		from = current.Status
+		// The provider does not guarantee delivery order
+		if created.Before(current.LastEventAt) {
+			h.ignorePaymentEvent(ctx, c, &event, "event is older than the last applied event")
+			return
+		}
+	}
+
+	// A plan change keeps the state whatever it is, so it is not a transition
+	to := models.PaymentEventStatus(event.Type, from)
+	switch {
+	case event.Type == "subscription.updated" && current == nil:
+		// The event that creates the subscription may still be on its way, and
+		// the provider retries on a conflict
+		c.JSON(http.StatusConflict, gin.H{"error": "No subscription to update yet, retry"})
+		return
+	case event.Type != "subscription.updated" && !models.CanTransition(from, to):
+		h.ignorePaymentEvent(ctx, c, &event, "subscription is "+describeStatus(from)+", cannot move to "+to)
+		return
+	}
+
+	next, err := h.nextSubscription(current, &event, to, created)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	if err := h.userRepo.SaveSubscription(ctx, user.UserID, next, current); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Not recording the event only means a redelivery is applied again,
+	// which the last_event_at check turns into a no-op
+	if err := h.paymentEventRepo.Record(ctx, processedPaymentEvent(&event, "applied")); err != nil {
+		log.Println("Failed to record payment event", event.ID+":", err)
+	}
+
+	c.JSON(http.StatusOK, PaymentWebhookResponse{Status: "applied", Subscription: next})
+}
+
+// nextSubscription applies an event to the current subscription, which may be nil
+func (h *SubscriptionHandler) nextSubscription(current *models.Subscription, event *models.PaymentEvent, status string, created time.Time) (*models.Subscription, error) {
+	next := &models.Subscription{}
+	if current != nil {
+		*next = *current
+	}
+	next.Status = status
+
+	data := event.Data
+	if data.Plan != "" {
+		next.Plan = data.Plan
+	}
+	if next.Plan == "" {
+		return nil, utils.NewAppError(http.StatusBadRequest, "Invalid event", "data.plan is required for a new subscription")
+	}
+	if data.CustomerID != "" {
+		next.ProviderCustomerID = data.CustomerID
+	}
+	if data.SubscriptionID != "" {
+		next.ProviderSubscriptionID = data.SubscriptionID
+	}
+	if data.TrialEndsAt != nil {
+		next.TrialEndsAt = data.TrialEndsAt
+	}
+	// A failed invoice reports the period it failed to pay for
+	if data.CurrentPeriodEnd != nil && event.Type != "invoice.payment_failed" {
+		next.CurrentPeriodEnd = data.CurrentPeriodEnd
+	}
+
+	grace := time.Duration(h.cfg.SubscriptionGraceDays) * 24 * time.Hour
+	// subscription.updated changes the plan and keeps the access period
+	switch event.Type {
+	case "subscription.trial_started":
+		if next.TrialEndsAt == nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Invalid event", "data.trial_ends_at is required to start a trial")
+		}
+		next.AccessUntil = *next.TrialEndsAt
+	case "invoice.paid":
+		if data.CurrentPeriodEnd == nil {
+			return nil, utils.NewAppError(http.StatusBadRequest, "Invalid event", "data.current_period_end is required for a paid invoice")
+		}
+		// A renewal paid a little late doesn't cut playback off in between
+		next.AccessUntil = data.CurrentPeriodEnd.Add(grace)
+	case "invoice.payment_failed":
+		// The provider retries the payment meanwhile. The grace counts from the
+		// end of the last paid period (or trial), so retries never extend it.
+		paidUntil := next.CurrentPeriodEnd
+		if current != nil && current.Status == models.SubscriptionTrial {
+			paidUntil = next.TrialEndsAt
+		}
+		if paidUntil != nil {
+			next.AccessUntil = paidUntil.Add(grace)
+		}
+	case "subscription.canceled":
+		// Access runs to the end of what was paid for, never past it
+		end := created
+		if data.CurrentPeriodEnd != nil {
+			end = *data.CurrentPeriodEnd
+		}
+		if current != nil && current.AccessUntil.Before(end) {
+			end = current.AccessUntil
+		}
+		next.AccessUntil = end
+	}
+
+	next.LastEventAt = created
+	next.UpdatedAt = time.Now()
+	return next, nil
+}
+
+// ignorePaymentEvent acknowledges an event without applying it, so the
+// provider stops redelivering it
+func (h *SubscriptionHandler) ignorePaymentEvent(ctx context.Context, c *gin.Context, event *models.PaymentEvent, reason string) {
+	log.Println("Ignoring payment event", event.ID+":", reason)
+	if err := h.paymentEventRepo.Record(ctx, processedPaymentEvent(event, "ignored")); err != nil {
+		log.Println("Failed to record payment event", event.ID+":", err)
+	}
+	c.JSON(http.StatusOK, PaymentWebhookResponse{Status: "ignored", Reason: reason})
+}
+
+func processedPaymentEvent(event *models.PaymentEvent, outcome string) *models.ProcessedPaymentEvent {
+	return &models.ProcessedPaymentEvent{
+		ID:          event.ID,
+		Type:        event.Type,
+		UserID:      event.Data.UserID,
+		Outcome:     outcome,
+		ProcessedAt: time.Now(),
+	}
+}
+
+func describeStatus(status string) string {
+	if status == "" {
+		return "not started"
+	}
+	return status
+}
+
+// planFeatures returns a plan with its configured features. Plans missing
+// from PLAN_MAX_STREAMS fall back to MAX_CONCURRENT_STREAMS, and plans
+// missing from PLAN_MAX_QUALITY are not capped.
+func planFeatures(cfg *config.Config, plan string) models.Plan {
+	features := models.Plan{
+		ID:         plan,
+		Name:       models.PlanName(plan),
+		MaxStreams: cfg.MaxConcurrentStreams,
+	}
+	if limit, ok := cfg.PlanMaxStreams[plan]; ok {
+		features.MaxStreams = limit
+	}
+	features.MaxQuality = cfg.PlanMaxQuality[plan]
+	return features
+}
+
+// checkEntitlement reports whether the plan may play the movie, writing a 403
+// naming the plan to upgrade to when it may not
+func checkEntitlement(c *gin.Context, plan string, movie *models.Movie) bool {
+	if models.PlanIncludes(plan, movie.MinPlan) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, EntitlementResponse{
+		Error:        "Upgrade to " + models.PlanName(movie.MinPlan) + " to watch this movie",
+		RequiredPlan: movie.MinPlan,
+		CurrentPlan:  plan,
+	})
+	return false
+}
+
+// withinQuality reports whether a rendition or video of the given height fits
+// a quality cap, 0 meaning no cap. Renditions and videos uploaded before
+// resolutions were required have no height and only play on plans without a
+// cap.
+func withinQuality(height, maxQuality int) bool {
+	return maxQuality == 0 || (height > 0 && height <= maxQuality)
+}
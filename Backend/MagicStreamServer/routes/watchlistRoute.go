@@ -0,0 +1,250 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+	authservice "github.com/afdhali/magic-stream/Backend/MagicStreamServer/controllers/auth"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/middleware"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/repositories"
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/utils"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WatchlistHandler handles the signed-in user's watchlist
+type WatchlistHandler struct {
+	tokenService  *authservice.TokenService
+	cfg           *config.Config
+	watchlistRepo repositories.WatchlistRepository
+	movieRepo     repositories.MovieRepository
+}
+
+// NewWatchlistHandler creates a new watchlist handler with dependencies injected
+func NewWatchlistHandler(ts *authservice.TokenService, cfg *config.Config, watchlistRepo repositories.WatchlistRepository, movieRepo repositories.MovieRepository) *WatchlistHandler {
+	return &WatchlistHandler{
+		tokenService:  ts,
+		cfg:           cfg,
+		watchlistRepo: watchlistRepo,
+		movieRepo:     movieRepo,
+	}
+}
+
+// WatchlistResponse for Swagger documentation
+type WatchlistResponse struct {
+	Data       []models.WatchlistItem `json:"data"`
+	Pagination PaginationInfo         `json:"pagination"`
+}
+
+// GetAll godoc
+// @Summary      Get my watchlist
+// @Description  Retrieve the movies the caller saved for later, in their chosen order. Movies in the trash are left out.
+// @Tags         Watchlist
+// @Security     BearerAuth
+// @Produce      json
+// @Param        limit query int false "Limit results (default 20, max 100)"
+// @Param        skip query int false "Skip results for pagination (default 0)"
+// @Success      200 {object} WatchlistResponse "Watchlist with pagination info"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/watchlist [get]
+func (h *WatchlistHandler) GetAll(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	profileID, _ := middleware.GetProfileID(c)
+
+	entries, err := h.watchlistRepo.FindByProfile(ctx, profileID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	ids := make([]bson.ObjectID, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.MovieID
+	}
+
+	movies, err := h.movieRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	chain := middleware.GetLocaleChain(c)
+	localizeMovies(movies, chain)
+	utils.ContentLanguage(c, chain)
+	moviesByID := make(map[bson.ObjectID]models.Movie, len(movies))
+	for _, movie := range movies {
+		moviesByID[movie.ID] = movie
+	}
+
+	items := []models.WatchlistItem{}
+	for position, entry := range entries {
+		if movie, ok := moviesByID[entry.MovieID]; ok {
+			items = append(items, watchlistItem(position, entry, movie))
+		}
+	}
+
+	pagination := utils.ParsePaginationParams(c.Query("limit"), c.Query("skip"), 20, 100)
+	total := int64(len(items))
+	start := min(pagination.Skip, total)
+	end := min(start+pagination.Limit, total)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       items[start:end],
+		"pagination": utils.CalculatePaginationInfo(total, pagination.Limit, pagination.Skip),
+	})
+}
+
+// Put godoc
+// @Summary      Save or move a watchlist movie
+// @Description  Add a movie to the caller's watchlist, or move it when a position is given. Positions start at 0; a position past the end moves the movie last.
+// @Tags         Watchlist
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        movie_id path string true "Movie ID"
+// @Param        watchlist body models.WatchlistRequest false "Optional position"
+// @Success      200 {object} models.WatchlistItem "Movie was already saved"
+// @Success      201 {object} models.WatchlistItem "Movie added"
+// @Failure      400 {object} ErrorResponse "Invalid ID or request body"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie not found"
+// @Failure      409 {object} ErrorResponse "Watchlist is full"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/watchlist/{movie_id} [put]
+func (h *WatchlistHandler) Put(c *gin.Context) {
+	movieID, err := bson.ObjectIDFromHex(c.Param("movie_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	// The body is optional, a bare PUT just saves the movie
+	var req models.WatchlistRequest
+	if c.Request.ContentLength != 0 && !utils.ValidateRequest(c, &req) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(middleware.ViewerContext(c), 5*time.Second)
+	defer cancel()
+
+	userID, _ := middleware.GetUserID(c)
+	profileID, _ := middleware.GetProfileID(c)
+
+	movie, err := h.movieRepo.FindByID(ctx, movieID.Hex())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	entries, err := h.watchlistRepo.FindByProfile(ctx, profileID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	index := -1
+	for i, entry := range entries {
+		if entry.MovieID == movieID {
+			index = i
+			break
+		}
+	}
+
+	created := index < 0
+	if created {
+		if h.cfg.WatchlistMaxItems > 0 && len(entries) >= h.cfg.WatchlistMaxItems {
+			utils.HandleError(c, repositories.ErrWatchlistFull)
+			return
+		}
+
+		entry := models.WatchlistEntry{
+			ID:        bson.NewObjectID(),
+			UserID:    userID,
+			ProfileID: profileID,
+			MovieID:   movieID,
+			AddedAt:   time.Now(),
+		}
+		if len(entries) > 0 {
+			entry.Position = entries[len(entries)-1].Position + 1
+		}
+		if err := h.watchlistRepo.Add(ctx, &entry); err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		entries = append(entries, entry)
+		index = len(entries) - 1
+	}
+
+	if req.Position != nil && *req.Position != index {
+		target := min(*req.Position, len(entries)-1)
+
+		moved := entries[index]
+		entries = append(entries[:index], entries[index+1:]...)
+		entries = append(entries[:target], append([]models.WatchlistEntry{moved}, entries[target:]...)...)
+
+		ids := make([]bson.ObjectID, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.MovieID
+		}
+		if err := h.watchlistRepo.Reorder(ctx, profileID, ids); err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+		index = target
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, watchlistItem(index, entries[index], *movie))
+}
+
+// Delete godoc
+// @Summary      Remove a watchlist movie
+// @Description  Take a movie off the caller's watchlist
+// @Tags         Watchlist
+// @Security     BearerAuth
+// @Produce      json
+// @Param        movie_id path string true "Movie ID"
+// @Success      200 {object} MessageResponse "Movie removed"
+// @Failure      400 {object} ErrorResponse "Invalid ID format"
+// @Failure      401 {object} ErrorResponse "Unauthorized"
+// @Failure      404 {object} ErrorResponse "Movie is not in the watchlist"
+// @Failure      500 {object} ErrorResponse "Internal server error"
+// @Router       /me/watchlist/{movie_id} [delete]
+func (h *WatchlistHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	movieID, err := bson.ObjectIDFromHex(c.Param("movie_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID format"})
+		return
+	}
+
+	profileID, _ := middleware.GetProfileID(c)
+	if err := h.watchlistRepo.Remove(ctx, profileID, movieID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Movie removed from watchlist"})
+}
+
+// watchlistItem pairs an entry at its place in the list with its movie
+func watchlistItem(position int, entry models.WatchlistEntry, movie models.Movie) models.WatchlistItem {
+	inWatchlist := true
+	movie.InWatchlist = &inWatchlist
+	return models.WatchlistItem{
+		Position: position,
+		AddedAt:  entry.AddedAt,
+		Movie:    movie,
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/config"
+)
+
+// Blob store backends selected by BLOB_STORE
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under a key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobStore keeps binary objects such as poster images under slash separated
+// keys. Blobs are written once and served from URL, so keys should change
+// whenever the content does. Blobs that must not be public, such as videos,
+// are read back through Open instead. Pass a size of -1 to Put when the
+// length of body is not known up front.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// NewBlobStore creates the blob store configured by BLOB_STORE
+func NewBlobStore(ctx context.Context, cfg *config.Config) (BlobStore, error) {
+	switch cfg.BlobStore {
+	case BackendLocal:
+		return NewLocalBlobStore(cfg.BlobLocalDir, cfg.BlobPublicURL)
+	case BackendS3:
+		return NewS3BlobStore(ctx, S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+			PublicURL: cfg.BlobPublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob store %q, use %s or %s", cfg.BlobStore, BackendLocal, BackendS3)
+	}
+}
+
+// validKey rejects keys that could escape the store's namespace
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
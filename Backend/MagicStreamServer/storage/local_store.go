@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalBlobStore keeps blobs as files under a directory. The server exposes
+// the directory itself (see main.go), so URLs point back at this instance.
+type LocalBlobStore struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalBlobStore creates a blob store rooted at dir, creating it if needed
+func NewLocalBlobStore(dir, publicURL string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStore{dir: dir, publicURL: publicURL}, nil
+}
+
+// Dir returns the directory blobs are stored in
+func (s *LocalBlobStore) Dir() string {
+	return s.dir
+}
+
+// Put writes the blob to a temporary file first so readers never see a partial file
+func (s *LocalBlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open returns the blob file itself, so responses copying from it can use sendfile
+func (s *LocalBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, BlobInfo, error) {
+	if err := validKey(key); err != nil {
+		return nil, BlobInfo{}, err
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, BlobInfo{}, ErrBlobNotFound
+		}
+		return nil, BlobInfo{}, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, BlobInfo{}, err
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, BlobInfo{}, ErrBlobNotFound
+	}
+
+	return file, BlobInfo{Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrBlobNotFound
+	}
+	return err
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Options configures an S3 compatible blob store such as AWS S3 or MinIO
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PublicURL string
+}
+
+// S3BlobStore keeps blobs in an S3 compatible bucket
+type S3BlobStore struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3BlobStore connects to the bucket, creating it if it does not exist yet.
+// Without PublicURL, blob URLs use path style addressing on the endpoint.
+func NewS3BlobStore(ctx context.Context, opts S3Options) (*S3BlobStore, error) {
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure: opts.UseSSL,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("checking bucket %s: %w", opts.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, opts.Bucket, minio.MakeBucketOptions{Region: opts.Region}); err != nil {
+			return nil, fmt.Errorf("creating bucket %s: %w", opts.Bucket, err)
+		}
+	}
+
+	publicURL := opts.PublicURL
+	if publicURL == "" {
+		scheme := "http"
+		if opts.UseSSL {
+			scheme = "https"
+		}
+		publicURL = fmt.Sprintf("%s://%s/%s", scheme, opts.Endpoint, opts.Bucket)
+	}
+
+	return &S3BlobStore{client: client, bucket: opts.Bucket, publicURL: publicURL}, nil
+}
+
+// Put uploads the blob; keys change with content, so it is cached as immutable
+func (s *S3BlobStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	opts := minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	}
+	if size < 0 {
+		// Without a size the client would buffer parts sized for a 5 TiB object
+		opts.PartSize = 16 << 20
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, body, size, opts)
+	return err
+}
+
+// Open returns the object lazily; reads after a seek fetch the matching byte range
+func (s *S3BlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, BlobInfo, error) {
+	if err := validKey(key); err != nil {
+		return nil, BlobInfo{}, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, BlobInfo{}, ErrBlobNotFound
+		}
+		return nil, BlobInfo{}, err
+	}
+
+	return object, BlobInfo{Size: stat.Size, ModTime: stat.LastModified}, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return ErrBlobNotFound
+		}
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3BlobStore) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+)
+
+// CSVListSeparator separates values of list columns such as genre_ids
+const CSVListSeparator = "|"
+
+// MovieCSVColumns is the flattened column layout shared by movie import and export
+var MovieCSVColumns = []string{
+	"imdb_id",
+	"title",
+	"poster_path",
+	"youtube_id",
+	"genre_ids",
+	"genre_names",
+	"admin_review",
+	"ranking_value",
+	"ranking_name",
+	"release_date",
+	"runtime",
+	"synopsis",
+	"original_language",
+	"spoken_languages",
+	"subtitle_languages",
+	"country",
+	"age_certification",
+	"min_plan",
+}
+
+// MovieCSVFieldAliases expands nested movie fields into their flattened CSV columns
+var MovieCSVFieldAliases = map[string][]string{
+	"genre":   {"genre_ids", "genre_names"},
+	"ranking": {"ranking_value", "ranking_name"},
+}
+
+// ExpandCSVFields resolves requested fields into CSV columns, defaulting to every column
+func ExpandCSVFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return MovieCSVColumns, nil
+	}
+
+	known := map[string]bool{"_id": true}
+	for _, column := range MovieCSVColumns {
+		known[column] = true
+	}
+
+	var columns []string
+	for _, field := range fields {
+		if expanded, ok := MovieCSVFieldAliases[field]; ok {
+			columns = append(columns, expanded...)
+			continue
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		columns = append(columns, field)
+	}
+
+	return columns, nil
+}
+
+// MovieCSVRecord flattens a movie into a CSV record with the given columns
+func MovieCSVRecord(movie *models.Movie, columns []string) []string {
+	record := make([]string, len(columns))
+
+	for i, column := range columns {
+		switch column {
+		case "_id":
+			record[i] = movie.ID.Hex()
+		case "imdb_id":
+			record[i] = movie.ImdbID
+		case "title":
+			record[i] = movie.Title
+		case "poster_path":
+			record[i] = movie.PosterPath
+		case "youtube_id":
+			record[i] = movie.YouTubeID
+		case "genre_ids":
+			ids := make([]string, len(movie.Genre))
+			for j, genre := range movie.Genre {
+				ids[j] = strconv.Itoa(genre.GenreID)
+			}
+			record[i] = strings.Join(ids, CSVListSeparator)
+		case "genre_names":
+			names := make([]string, len(movie.Genre))
+			for j, genre := range movie.Genre {
+				names[j] = genre.GenreName
+			}
+			record[i] = strings.Join(names, CSVListSeparator)
+		case "admin_review":
+			record[i] = movie.AdminReview
+		case "ranking_value":
+			record[i] = strconv.Itoa(movie.Ranking.RankingValue)
+		case "ranking_name":
+			record[i] = movie.Ranking.RankingName
+		case "release_date":
+			record[i] = movie.ReleaseDate
+		case "runtime":
+			if movie.Runtime > 0 {
+				record[i] = strconv.Itoa(movie.Runtime)
+			}
+		case "synopsis":
+			record[i] = movie.Synopsis
+		case "original_language":
+			record[i] = movie.OriginalLanguage
+		case "spoken_languages":
+			record[i] = strings.Join(movie.SpokenLanguages, CSVListSeparator)
+		case "subtitle_languages":
+			record[i] = strings.Join(movie.SubtitleLanguages, CSVListSeparator)
+		case "country":
+			record[i] = movie.Country
+		case "age_certification":
+			record[i] = movie.AgeCertification
+		case "min_plan":
+			record[i] = movie.MinPlan
+		}
+	}
+
+	return record
+}
+
+// MovieRequestFromCSV builds a create request from a CSV record keyed by the header row.
+// Genre names are left empty and are expected to be resolved against the genres collection.
+func MovieRequestFromCSV(header, record []string) (models.MovieCreateRequest, error) {
+	var req models.MovieCreateRequest
+
+	if len(record) != len(header) {
+		return req, fmt.Errorf("expected %d columns, got %d", len(header), len(record))
+	}
+
+	for i, column := range header {
+		value := SanitizeString(record[i])
+
+		switch strings.ToLower(SanitizeString(column)) {
+		case "imdb_id":
+			req.ImdbID = value
+		case "title":
+			req.Title = value
+		case "poster_path":
+			req.PosterPath = value
+		case "youtube_id":
+			req.YouTubeID = value
+		case "genre_ids":
+			for _, part := range splitCSVList(value) {
+				genreID, err := strconv.Atoi(part)
+				if err != nil {
+					return req, fmt.Errorf("invalid genre id %q", part)
+				}
+				req.Genre = append(req.Genre, models.Genre{GenreID: genreID})
+			}
+		case "admin_review":
+			req.AdminReview = value
+		case "ranking_value":
+			if value != "" {
+				rankingValue, err := strconv.Atoi(value)
+				if err != nil {
+					return req, fmt.Errorf("invalid ranking_value %q", value)
+				}
+				req.Ranking.RankingValue = rankingValue
+			}
+		case "ranking_name":
+			req.Ranking.RankingName = value
+		case "release_date":
+			req.ReleaseDate = value
+		case "runtime":
+			if value != "" {
+				runtime, err := strconv.Atoi(value)
+				if err != nil {
+					return req, fmt.Errorf("invalid runtime %q", value)
+				}
+				req.Runtime = runtime
+			}
+		case "synopsis":
+			req.Synopsis = value
+		case "original_language":
+			req.OriginalLanguage = value
+		case "spoken_languages":
+			req.SpokenLanguages = splitCSVList(value)
+		case "subtitle_languages":
+			req.SubtitleLanguages = splitCSVList(value)
+		case "country":
+			req.Country = value
+		case "age_certification":
+			req.AgeCertification = value
+		case "min_plan":
+			req.MinPlan = value
+		}
+	}
+
+	return req, nil
+}
+
+// splitCSVList splits a list column, dropping empty entries
+func splitCSVList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, CSVListSeparator) {
+		if part = SanitizeString(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
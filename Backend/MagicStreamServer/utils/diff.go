@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+)
+
+// DiffMovies returns the top-level fields that differ between two movie states,
+// compared by their JSON representation. A nil movie counts as having no fields.
+// The version counter is ignored since it changes on every write.
+func DiffMovies(before, after *models.Movie) ([]models.FieldChange, error) {
+	oldFields, err := movieFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := movieFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+	delete(keys, "_id")
+	delete(keys, "version")
+	delete(keys, "updated_at")
+	delete(keys, "user_rating")
+
+	changes := []models.FieldChange{}
+	for key := range keys {
+		if !reflect.DeepEqual(oldFields[key], newFields[key]) {
+			changes = append(changes, models.FieldChange{Field: key, Old: oldFields[key], New: newFields[key]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+
+	return changes, nil
+}
+
+func movieFields(movie *models.Movie) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if movie == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(movie)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -57,12 +57,50 @@ func HandleError(c *gin.Context, err error) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 	case repositories.ErrUserAlreadyExists:
 		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+	case repositories.ErrProfileNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+	case repositories.ErrProfileLimitReached:
+		c.JSON(http.StatusConflict, gin.H{"error": "Profile limit reached, delete a profile first"})
+	case repositories.ErrLastProfile:
+		c.JSON(http.StatusConflict, gin.H{"error": "An account needs at least one profile"})
+	case repositories.ErrSubscriptionChanged:
+		c.JSON(http.StatusConflict, gin.H{"error": "Subscription changed meanwhile, retry"})
 	case repositories.ErrMovieNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Movie not found"})
 	case repositories.ErrMovieAlreadyExists:
 		c.JSON(http.StatusConflict, gin.H{"error": "Movie already exists"})
 	case repositories.ErrGenreNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Genre not found"})
+	case repositories.ErrPersonNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+	case repositories.ErrPersonInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": "Person is credited on one or more movies"})
+	case repositories.ErrRevisionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	case repositories.ErrRankingNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ranking not found"})
+	case repositories.ErrRankingAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "Ranking already exists"})
+	case repositories.ErrRankingInUse:
+		c.JSON(http.StatusConflict, gin.H{"error": "Ranking is assigned to one or more movies"})
+	case repositories.ErrReviewNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+	case repositories.ErrReviewAlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "You already reviewed this movie, update your review instead"})
+	case repositories.ErrWatchlistEntryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Movie is not in the watchlist"})
+	case repositories.ErrWatchlistEntryExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "Movie is already in the watchlist"})
+	case repositories.ErrWatchlistFull:
+		c.JSON(http.StatusConflict, gin.H{"error": "Watchlist is full, remove a movie first"})
+	case repositories.ErrCollectionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+	case repositories.ErrPlaylistNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist not found"})
+	case repositories.ErrPlaybackSessionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playback session not found or has ended"})
+	case repositories.ErrVersionConflict:
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Movie was modified since it was read"})
 	case repositories.ErrRefreshTokenNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "Refresh token not found"})
 	default:
@@ -80,4 +118,4 @@ func ValidateRequest(c *gin.Context, data interface{}) bool {
 		return false
 	}
 	return true
-}
\ No newline at end of file
+}
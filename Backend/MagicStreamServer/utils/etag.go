@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/gin-gonic/gin"
+)
+
+// MovieETag returns the strong entity tag for a movie at the given version
+func MovieETag(version int64) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// LocalizedMovieETag returns the strong entity tag for a movie read in a locale
+// chain, e.g. "v12-id.en". Text falls back along the chain, so all of it is
+// part of the tag.
+func LocalizedMovieETag(version int64, chain models.LocaleChain) string {
+	if len(chain) == 0 {
+		return MovieETag(version)
+	}
+	return fmt.Sprintf(`"v%d-%s"`, version, strings.Join(chain, "."))
+}
+
+// MovieIfMatchSatisfied reports whether an If-Match header value matches a movie
+// at the given version, taking the bare tag as well as any localized one. It
+// uses strong comparison, so weak (W/) tags never match.
+func MovieIfMatchSatisfied(header string, version int64) bool {
+	etag := MovieETag(version)
+	localized := fmt.Sprintf(`"v%d-`, version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+		if strings.HasPrefix(candidate, localized) && strings.HasSuffix(candidate, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionETag returns a strong entity tag for a response derived from a
+// collection state and the request parameters that shaped it
+func CollectionETag(state *models.CollectionState, params string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%d|%d|%s", state.Count, state.Revision, state.LastModified.UnixNano(), params)))
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// Personalized marks a read that depends on the caller. The response varies by
+// Authorization and is never stored, so it is not answered with 304 either.
+func Personalized(c *gin.Context) {
+	c.Header("Cache-Control", "private, no-store")
+	c.Writer.Header().Add("Vary", "Authorization")
+}
+
+// NotModified writes the cache headers of a successful read and answers
+// If-None-Match / If-Modified-Since. When the client's copy is still fresh it
+// responds 304 Not Modified and returns true, and the handler must stop.
+func NotModified(c *gin.Context, cacheControl, etag string, lastModified time.Time) bool {
+	if cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
+	}
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match takes precedence and uses weak comparison
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				c.Status(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -36,8 +38,9 @@ func ParsePaginationParams(limitStr, skipStr string, defaultLimit, maxLimit int)
 	}
 }
 
-// BuildMovieFilter builds a MongoDB filter for movie queries
-func BuildMovieFilter(genreFilter, rankingFilter string) bson.M {
+// BuildMovieFilter builds a MongoDB filter for movie queries. A positive
+// rankingOrder keeps movies ranked at least that well.
+func BuildMovieFilter(genreFilter string, rankingOrder int) bson.M {
 	filter := bson.M{}
 
 	if genreFilter != "" {
@@ -47,15 +50,88 @@ func BuildMovieFilter(genreFilter, rankingFilter string) bson.M {
 		}
 	}
 
-	if rankingFilter != "" {
-		if rankingValue, err := strconv.Atoi(rankingFilter); err == nil && rankingValue > 0 {
-			filter["ranking.ranking_value"] = bson.M{"$gte": rankingValue}
-		}
+	if rankingOrder > 0 {
+		filter["ranking.order"] = bson.M{"$lte": rankingOrder}
+	}
+
+	return filter
+}
+
+// ApplyMovieMetadataFilter adds the release year, runtime, language, country,
+// certification and credit filters to an existing movie filter
+func ApplyMovieMetadataFilter(filter bson.M, params models.MovieMetadataFilter) bson.M {
+	releaseDate := bson.M{}
+	if params.YearFrom > 0 {
+		releaseDate["$gte"] = fmt.Sprintf("%04d-01-01", params.YearFrom)
+	}
+	if params.YearTo > 0 {
+		releaseDate["$lte"] = fmt.Sprintf("%04d-12-31", params.YearTo)
+	}
+	if len(releaseDate) > 0 {
+		filter["release_date"] = releaseDate
+	}
+
+	runtime := bson.M{}
+	if params.MinRuntime > 0 {
+		runtime["$gte"] = params.MinRuntime
+	}
+	if params.MaxRuntime > 0 {
+		runtime["$lte"] = params.MaxRuntime
+	}
+	if len(runtime) > 0 {
+		filter["runtime"] = runtime
+	}
+
+	if params.Language != "" {
+		AddAndClause(filter, bson.M{"$or": bson.A{
+			bson.M{"original_language": params.Language},
+			bson.M{"spoken_languages": params.Language},
+		}})
+	}
+	if params.Subtitle != "" {
+		// Declared subtitle languages or an uploaded track
+		AddAndClause(filter, bson.M{"$or": bson.A{
+			bson.M{"subtitle_languages": params.Subtitle},
+			bson.M{"subtitles.lang": params.Subtitle},
+		}})
+	}
+	if params.Country != "" {
+		filter["country"] = params.Country
+	}
+	if params.Certification != "" {
+		filter["age_certification"] = params.Certification
+	}
+	if director := SanitizeString(params.Director); director != "" {
+		AddAndClause(filter, creditFilter(models.JobDirector, director))
+	}
+	if cast := SanitizeString(params.Cast); cast != "" {
+		AddAndClause(filter, creditFilter(models.JobActor, cast))
+	}
+	if params.MinRating > 0 {
+		filter["user_rating.average"] = bson.M{"$gte": params.MinRating}
+	}
+	if params.MinRatings > 0 {
+		filter["user_rating.count"] = bson.M{"$gte": params.MinRatings}
 	}
 
 	return filter
 }
 
+// AddAndClause appends a clause to the filter's $and list so several
+// conditions on the same field can be combined
+func AddAndClause(filter bson.M, clause bson.M) {
+	and, _ := filter["$and"].(bson.A)
+	filter["$and"] = append(and, clause)
+}
+
+// creditFilter matches movies crediting a person whose name contains the search term
+func creditFilter(job, name string) bson.M {
+	return bson.M{"credits": bson.M{"$elemMatch": bson.M{
+		"job":  job,
+		"name": bson.M{"$regex": regexp.QuoteMeta(name), "$options": "i"},
+	}}}
+}
+
 // ValidateGenres checks if genres are valid (helper function)
 func ValidateGenres(genres []models.Genre) bool {
 	if len(genres) == 0 {
@@ -105,4 +181,4 @@ func ExtractGenreIDs(genres []models.Genre) []int {
 		ids[i] = genre.GenreID
 	}
 	return ids
-}
\ No newline at end of file
+}
@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+)
+
+// HLSPlaylistContentType is the media type of master and media playlists
+const HLSPlaylistContentType = "application/vnd.apple.mpegurl"
+
+var ErrInvalidPlaylist = errors.New("invalid media playlist")
+
+// hlsSubtitleGroup is the rendition group every subtitle track belongs to
+const hlsSubtitleGroup = "subs"
+
+// hlsFileName restricts segment URIs to plain file names next to the playlist
+var hlsFileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// hlsSegmentTypes maps the accepted segment extensions to their content types
+var hlsSegmentTypes = map[string]string{
+	".ts":  "video/mp2t",
+	".m4s": "video/iso.segment",
+	".mp4": "video/mp4",
+	".aac": "audio/aac",
+	".m4a": "audio/mp4",
+}
+
+// MediaPlaylist is the segment list read from an uploaded HLS media playlist
+type MediaPlaylist struct {
+	InitSegment    string
+	Segments       []models.HLSSegment
+	TargetDuration int
+}
+
+// HLSSegmentContentType returns the content type of a segment file name,
+// reporting false for files that can't be an HLS segment
+func HLSSegmentContentType(name string) (string, bool) {
+	if !hlsFileName.MatchString(name) {
+		return "", false
+	}
+	contentType, ok := hlsSegmentTypes[strings.ToLower(path.Ext(name))]
+	return contentType, ok
+}
+
+// ParseMediaPlaylist reads the segments of a VOD media playlist, as written by
+// ffmpeg or other packagers. Segment URIs must be plain file names, since the
+// segments are uploaded alongside the playlist. Encrypted and byte range
+// playlists are rejected.
+func ParseMediaPlaylist(data []byte) (*MediaPlaylist, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidPlaylist, fmt.Sprintf(format, args...))
+	}
+
+	playlist := &MediaPlaylist{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	header := false
+	duration := -1.0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !header {
+			if strings.TrimPrefix(line, "\ufeff") != "#EXTM3U" {
+				return nil, invalid("missing #EXTM3U header")
+			}
+			header = true
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed <= 0 || math.IsInf(parsed, 0) {
+				return nil, invalid("bad segment duration %q", value)
+			}
+			duration = parsed
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			attributes := parseHLSAttributes(strings.TrimPrefix(line, "#EXT-X-MAP:"))
+			if _, ok := attributes["BYTERANGE"]; ok {
+				return nil, invalid("byte range segments are not supported")
+			}
+			if _, ok := HLSSegmentContentType(attributes["URI"]); !ok {
+				return nil, invalid("init segment %q must be a segment file next to the playlist", attributes["URI"])
+			}
+			playlist.InitSegment = attributes["URI"]
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			if parseHLSAttributes(strings.TrimPrefix(line, "#EXT-X-KEY:"))["METHOD"] != "NONE" {
+				return nil, invalid("encrypted playlists are not supported")
+			}
+		case strings.HasPrefix(line, "#EXT-X-BYTERANGE:"):
+			return nil, invalid("byte range segments are not supported")
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			return nil, invalid("this is a master playlist, upload the media playlist of a single rendition")
+		case strings.HasPrefix(line, "#"):
+			// Other tags are regenerated by the server or don't apply to VOD
+		default:
+			if duration < 0 {
+				return nil, invalid("segment %q has no #EXTINF duration", line)
+			}
+			if _, ok := HLSSegmentContentType(line); !ok {
+				return nil, invalid("segment %q must be a .ts, .m4s, .mp4, .aac or .m4a file next to the playlist", line)
+			}
+			playlist.Segments = append(playlist.Segments, models.HLSSegment{URI: line, Duration: duration})
+			// Players compare durations rounded to whole seconds against the target
+			playlist.TargetDuration = max(playlist.TargetDuration, int(math.Round(duration)))
+			duration = -1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, invalid("%v", err)
+	}
+	if len(playlist.Segments) == 0 {
+		return nil, invalid("no segments")
+	}
+
+	return playlist, nil
+}
+
+// MediaPlaylistM3U8 writes the media playlist of a rendition. Segment URIs are
+// relative to the playlist URL, each prefixed with segmentDir.
+func MediaPlaylistM3U8(playlist *models.HLSPlaylist, segmentDir string) string {
+	version := 3
+	if playlist.InitSegment != "" {
+		version = 6 // EXT-X-MAP outside I-frame playlists
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&b, "#EXT-X-VERSION:%d\n", version)
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", playlist.TargetDuration)
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	if playlist.InitSegment != "" {
+		fmt.Fprintf(&b, "#EXT-X-MAP:URI=%q\n", segmentDir+playlist.InitSegment)
+	}
+	for _, segment := range playlist.Segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s%s\n", segment.Duration, segmentDir, segment.URI)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// MasterPlaylistM3U8 writes the master playlist of a movie, lowest bandwidth
+// first. Each rendition's media playlist is at <name>/index.m3u8, and each
+// subtitle track's at subtitles/<lang>/<kind>.m3u8.
+func MasterPlaylistM3U8(renditions map[string]models.HLSRendition, subtitles []models.SubtitleTrack) string {
+	names := make([]string, 0, len(renditions))
+	for name := range renditions {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := renditions[names[i]], renditions[names[j]]
+		if a.Bandwidth != b.Bandwidth {
+			return a.Bandwidth < b.Bandwidth
+		}
+		return names[i] < names[j]
+	})
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+
+	// Names must be unique within the group
+	seen := map[string]bool{}
+	for _, track := range subtitles {
+		name := track.Label
+		if seen[name] {
+			name += " (" + track.Kind + ")"
+		}
+		seen[name] = true
+
+		attributes := []string{
+			"TYPE=SUBTITLES",
+			fmt.Sprintf("GROUP-ID=%q", hlsSubtitleGroup),
+			fmt.Sprintf("NAME=%q", strings.ReplaceAll(name, `"`, "'")),
+			fmt.Sprintf("LANGUAGE=%q", track.Lang),
+			"DEFAULT=NO",
+			"AUTOSELECT=YES",
+		}
+		if track.Kind == models.SubtitleKindCaptions {
+			attributes = append(attributes, `CHARACTERISTICS="public.accessibility.transcribes-spoken-dialog,public.accessibility.describes-music-and-sound"`)
+		}
+		attributes = append(attributes, fmt.Sprintf("URI=%q", "subtitles/"+track.Lang+"/"+track.Kind+".m3u8"))
+		fmt.Fprintf(&b, "#EXT-X-MEDIA:%s\n", strings.Join(attributes, ","))
+	}
+
+	for _, name := range names {
+		rendition := renditions[name]
+		attributes := []string{"BANDWIDTH=" + strconv.Itoa(rendition.Bandwidth)}
+		if rendition.AverageBandwidth > 0 {
+			attributes = append(attributes, "AVERAGE-BANDWIDTH="+strconv.Itoa(rendition.AverageBandwidth))
+		}
+		if rendition.Width > 0 && rendition.Height > 0 {
+			attributes = append(attributes, fmt.Sprintf("RESOLUTION=%dx%d", rendition.Width, rendition.Height))
+		}
+		if rendition.Codecs != "" {
+			attributes = append(attributes, fmt.Sprintf("CODECS=%q", rendition.Codecs))
+		}
+		if rendition.FrameRate > 0 {
+			attributes = append(attributes, "FRAME-RATE="+strconv.FormatFloat(rendition.FrameRate, 'f', 3, 64))
+		}
+		if len(subtitles) > 0 {
+			attributes = append(attributes, fmt.Sprintf("SUBTITLES=%q", hlsSubtitleGroup))
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:%s\n%s/index.m3u8\n", strings.Join(attributes, ","), name)
+	}
+	return b.String()
+}
+
+// SubtitlePlaylistM3U8 writes the media playlist of a subtitle track, with the
+// whole WebVTT file as a single segment spanning the movie
+func SubtitlePlaylistM3U8(uri string, duration float64) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(duration)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", duration, uri)
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return b.String()
+}
+
+// parseHLSAttributes reads an attribute list such as METHOD=NONE,URI="init.mp4"
+func parseHLSAttributes(list string) map[string]string {
+	attributes := map[string]string{}
+	for list != "" {
+		name, rest, found := strings.Cut(list, "=")
+		if !found {
+			break
+		}
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+
+		attributes[strings.TrimSpace(name)] = value
+		list = rest
+	}
+	return attributes
+}
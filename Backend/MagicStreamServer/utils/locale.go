@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/afdhali/magic-stream/Backend/MagicStreamServer/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Locales is the set of locales the catalog is translated into. The default
+// locale is the language of the untranslated movie and genre fields.
+type Locales struct {
+	defaultLocale language.Tag
+	supported     []language.Tag
+	matcher       language.Matcher
+}
+
+// NewLocales parses the default and supported locales. The default locale is
+// always supported, and listed first so it wins ties in negotiation.
+func NewLocales(defaultLocale string, supported []string) (*Locales, error) {
+	def, err := language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default locale %q: %w", defaultLocale, err)
+	}
+
+	tags := []language.Tag{def}
+	for _, locale := range supported {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supported locale %q: %w", locale, err)
+		}
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return &Locales{defaultLocale: def, supported: tags, matcher: language.NewMatcher(tags)}, nil
+}
+
+// Default returns the default locale
+func (l *Locales) Default() string {
+	return l.defaultLocale.String()
+}
+
+// Supported returns every supported locale, the default first
+func (l *Locales) Supported() []string {
+	locales := make([]string, len(l.supported))
+	for i, tag := range l.supported {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+// Negotiate builds the fallback chain of a request. An explicit lang value wins
+// over the Accept-Language header; each preferred language adds the closest
+// supported locale and its supported parents, and the chain ends at the
+// default locale.
+func (l *Locales) Negotiate(lang, acceptLanguage string) models.LocaleChain {
+	var preferred []language.Tag
+	if lang != "" {
+		for _, part := range strings.Split(lang, ",") {
+			if tag, err := language.Parse(strings.TrimSpace(part)); err == nil {
+				preferred = append(preferred, tag)
+			}
+		}
+	} else if acceptLanguage != "" {
+		// Sorted by quality, invalid entries are dropped
+		preferred, _, _ = language.ParseAcceptLanguage(acceptLanguage)
+	}
+
+	chain := models.LocaleChain{}
+	for _, tag := range preferred {
+		_, index, confidence := l.matcher.Match(tag)
+		if confidence == language.No {
+			continue
+		}
+		matched := l.supported[index]
+		if matched == l.defaultLocale {
+			break
+		}
+
+		// pt-BR falls back to pt when both are supported
+		for tag := matched; !tag.IsRoot(); tag = tag.Parent() {
+			if tag != l.defaultLocale && containsTag(l.supported, tag) && !containsLocale(chain, tag.String()) {
+				chain = append(chain, tag.String())
+			}
+		}
+	}
+
+	return append(chain, l.defaultLocale.String())
+}
+
+// TranslationKey canonicalizes the locale of a translation. Translations must be
+// in a supported locale other than the default, whose text lives in the
+// untranslated fields.
+func (l *Locales) TranslationKey(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", NewAppError(http.StatusBadRequest, "Invalid translation locale", locale)
+	}
+	if tag == l.defaultLocale {
+		return "", NewAppError(http.StatusBadRequest, "Translations can't use the default locale, set the untranslated fields instead", locale)
+	}
+	if !containsTag(l.supported, tag) {
+		return "", NewAppError(http.StatusBadRequest, "Unsupported translation locale. Supported: "+strings.Join(l.Supported(), ", "), locale)
+	}
+	return tag.String(), nil
+}
+
+// MovieTranslations canonicalizes the locales of a movie's translations and
+// drops translations without any text
+func (l *Locales) MovieTranslations(translations map[string]models.MovieTranslation) (map[string]models.MovieTranslation, error) {
+	if len(translations) == 0 {
+		return nil, nil
+	}
+
+	canonical := make(map[string]models.MovieTranslation, len(translations))
+	for locale, translation := range translations {
+		key, err := l.TranslationKey(locale)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := canonical[key]; ok {
+			return nil, NewAppError(http.StatusBadRequest, "Duplicate translation locale", key)
+		}
+		if translation != (models.MovieTranslation{}) {
+			canonical[key] = translation
+		}
+	}
+
+	if len(canonical) == 0 {
+		return nil, nil
+	}
+	return canonical, nil
+}
+
+// ContentLanguage marks a localized response with its language and, since the
+// locale may come from Accept-Language, tells caches the response varies by it
+func ContentLanguage(c *gin.Context, chain models.LocaleChain) {
+	if locale := chain.Primary(); locale != "" {
+		c.Header("Content-Language", locale)
+	}
+	c.Writer.Header().Add("Vary", "Accept-Language")
+}
+
+// LocaleParams returns the locale chain in a form suitable for CollectionETag params
+func LocaleParams(chain models.LocaleChain) string {
+	return "|lang=" + strings.Join(chain, ",")
+}
+
+func containsTag(tags []language.Tag, tag language.Tag) bool {
+	for _, existing := range tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLocale(chain models.LocaleChain, locale string) bool {
+	for _, existing := range chain {
+		if existing == locale {
+			return true
+		}
+	}
+	return false
+}
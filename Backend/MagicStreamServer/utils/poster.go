@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+	"sort"
+
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Poster renditions are encoded in both formats when the build can encode WebP;
+// clients pick WebP when they support it
+const (
+	PosterFormatJPEG = "jpeg"
+	PosterFormatWebP = "webp"
+)
+
+// maxPosterPixels bounds the decoded size of an upload, so a small file cannot
+// claim huge dimensions and exhaust memory while decoding
+const maxPosterPixels = 40_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type, use JPEG, PNG or WebP")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+var posterContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// PosterRendition is one resized and encoded copy of an uploaded poster
+type PosterRendition struct {
+	Width       int
+	Height      int
+	Format      string
+	ContentType string
+	Data        []byte
+}
+
+// SniffImageType detects the type of an uploaded image from its content,
+// ignoring whatever the client claimed
+func SniffImageType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !posterContentTypes[contentType] {
+		return contentType, ErrUnsupportedImage
+	}
+	return contentType, nil
+}
+
+// RenderPoster decodes a poster and renders it at each width as JPEG, and as
+// WebP in cgo builds.
+// Images are never upscaled: widths beyond the original collapse into a single
+// rendition at the original width. It returns the original dimensions.
+func RenderPoster(data []byte, widths []int) (int, int, []PosterRendition, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPosterPixels {
+		return 0, 0, nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, ErrUnsupportedImage
+	}
+
+	targets := map[int]bool{}
+	for _, width := range widths {
+		targets[min(width, config.Width)] = true
+	}
+	sorted := make([]int, 0, len(targets))
+	for width := range targets {
+		sorted = append(sorted, width)
+	}
+	sort.Ints(sorted)
+
+	var renditions []PosterRendition
+	for _, width := range sorted {
+		height := max(1, config.Height*width/config.Width)
+
+		// Flatten onto white so transparent PNGs do not turn black in JPEG
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+		var jpegData bytes.Buffer
+		if err := jpeg.Encode(&jpegData, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return 0, 0, nil, err
+		}
+		renditions = append(renditions,
+			PosterRendition{Width: width, Height: height, Format: PosterFormatJPEG, ContentType: "image/jpeg", Data: jpegData.Bytes()},
+		)
+
+		if posterWebP {
+			var webpData bytes.Buffer
+			if err := encodeWebP(&webpData, dst); err != nil {
+				return 0, 0, nil, err
+			}
+			renditions = append(renditions,
+				PosterRendition{Width: width, Height: height, Format: PosterFormatWebP, ContentType: "image/webp", Data: webpData.Bytes()},
+			)
+		}
+	}
+
+	return config.Width, config.Height, renditions, nil
+}
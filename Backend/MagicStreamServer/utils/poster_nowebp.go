@@ -0,0 +1,17 @@
+//go:build !cgo
+
+package utils
+
+import (
+	"errors"
+	"image"
+	"io"
+)
+
+// posterWebP reports whether posters are also encoded as WebP. Builds without
+// cgo have no libwebp and store JPEG sizes only.
+const posterWebP = false
+
+func encodeWebP(io.Writer, image.Image) error {
+	return errors.New("WebP encoding needs a cgo build")
+}
@@ -0,0 +1,18 @@
+//go:build cgo
+
+package utils
+
+import (
+	"image"
+	"io"
+
+	"github.com/chai2010/webp"
+)
+
+// posterWebP reports whether posters are also encoded as WebP. The encoder
+// wraps libwebp, so only cgo builds have it.
+const posterWebP = true
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, &webp.Options{Quality: 80})
+}
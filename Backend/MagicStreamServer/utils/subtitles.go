@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// WebVTTContentType is the media type subtitle tracks are stored and served with
+const WebVTTContentType = "text/vtt; charset=utf-8"
+
+// Subtitle formats accepted on upload
+const (
+	SubtitleFormatSRT    = "srt"
+	SubtitleFormatWebVTT = "vtt"
+)
+
+var ErrInvalidSubtitle = errors.New("subtitle file must be SRT or WebVTT with at least one cue")
+
+// srtTiming matches the timing line of an SRT cue. Some tools write a dot
+// instead of a comma, or append position coordinates, which are dropped.
+var srtTiming = regexp.MustCompile(`^(\d{1,3}):(\d{1,2}):(\d{1,2})[,.](\d{1,3})\s*-->\s*(\d{1,3}):(\d{1,2}):(\d{1,2})[,.](\d{1,3})`)
+
+// srtUnsupportedMarkup matches the <font> tags and {\an8} style overrides that
+// SRT editors write but WebVTT does not understand
+var srtUnsupportedMarkup = regexp.MustCompile(`(?i)</?font[^>]*>|\{\\[^}]*\}`)
+
+// SubtitleToWebVTT converts an uploaded SRT or WebVTT file to UTF-8 WebVTT with
+// LF line endings and returns the format it was read as. Files that are not
+// UTF-8 are read as UTF-16 when they start with its byte order mark, and as
+// Windows-1252 otherwise, the usual encoding of older SRT files.
+func SubtitleToWebVTT(data []byte) ([]byte, string, error) {
+	text, err := decodeSubtitleText(data)
+	if err != nil {
+		return nil, "", ErrInvalidSubtitle
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if isWebVTT(text) {
+		if !strings.Contains(text, "-->") {
+			return nil, "", ErrInvalidSubtitle
+		}
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return []byte(text), SubtitleFormatWebVTT, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	cues := 0
+	for _, block := range splitSubtitleBlocks(text) {
+		lines := strings.Split(block, "\n")
+		if _, err := strconv.Atoi(strings.TrimSpace(lines[0])); err == nil && len(lines) > 1 {
+			lines = lines[1:] // cue number
+		}
+
+		timing := srtTiming.FindStringSubmatch(strings.TrimSpace(lines[0]))
+		if timing == nil {
+			return nil, "", ErrInvalidSubtitle
+		}
+
+		var cueText []string
+		for _, line := range lines[1:] {
+			line = strings.TrimSpace(srtUnsupportedMarkup.ReplaceAllString(line, ""))
+			if line != "" {
+				// A cue's text must not contain the timing arrow
+				cueText = append(cueText, strings.ReplaceAll(line, "-->", "->"))
+			}
+		}
+		if len(cueText) == 0 {
+			continue
+		}
+
+		start, ok := vttTimestamp(timing[1:5])
+		if !ok {
+			return nil, "", ErrInvalidSubtitle
+		}
+		end, ok := vttTimestamp(timing[5:9])
+		if !ok {
+			return nil, "", ErrInvalidSubtitle
+		}
+
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n", start, end, strings.Join(cueText, "\n"))
+		cues++
+	}
+
+	if cues == 0 {
+		return nil, "", ErrInvalidSubtitle
+	}
+	return []byte(b.String()), SubtitleFormatSRT, nil
+}
+
+// decodeSubtitleText returns the file as a string without a byte order mark
+func decodeSubtitleText(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		data = data[3:]
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	}
+
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// isWebVTT reports whether text starts with the WebVTT signature line
+func isWebVTT(text string) bool {
+	if !strings.HasPrefix(text, "WEBVTT") {
+		return false
+	}
+	rest := text[len("WEBVTT"):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n'
+}
+
+// splitSubtitleBlocks splits SRT text into cues at blank lines
+func splitSubtitleBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// vttTimestamp formats hours, minutes, seconds and milliseconds as HH:MM:SS.mmm.
+// It reports false for minutes or seconds out of range.
+func vttTimestamp(parts []string) (string, bool) {
+	// The fraction is of a second, so "5" is 500 milliseconds
+	fraction := (parts[3] + "00")[:3]
+
+	values := make([]int, 0, len(parts))
+	for _, part := range append(parts[:3:3], fraction) {
+		value, _ := strconv.Atoi(part)
+		values = append(values, value)
+	}
+	if values[1] >= 60 || values[2] >= 60 {
+		return "", false
+	}
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", values[0], values[1], values[2], values[3]), true
+}